@@ -0,0 +1,141 @@
+package termy
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mec-nyan/termy/printer"
+)
+
+func TestFlushPolicy(t *testing.T) {
+	var out bytes.Buffer
+	d, err := NewDisplayWith(
+		WithInput(strings.NewReader("a")),
+		WithOutput(&out),
+		WithoutTermios(),
+		WithEscapes(true),
+		WithFlushPolicy(printer.FlushManually),
+	)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	d.MoveTo(1, 2)
+	d.Print("hi")
+	d.MoveUp(3)
+	d.EnableFocusReporting()
+	if got := out.String(); got != "" {
+		t.Errorf("err: want nothing written yet, got (%q)", got)
+	}
+
+	// Everything is shown before waiting for the user.
+	if _, err := d.ReadEvent(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	want := "\x1b[2;1H" + "hi" + "\x1b[3A" + "\x1b[?1004h"
+	if got := out.String(); got != want {
+		t.Errorf("err: want (%q), got (%q)", want, got)
+	}
+
+	// And on Restore.
+	out.Reset()
+	if err := d.Restore(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if got, want := out.String(), "\x1b[?1004l"; got != want {
+		t.Errorf("err: want (%q), got (%q)", want, got)
+	}
+}
+
+func TestFlushPolicyQuery(t *testing.T) {
+	d, out := newReplyDisplay(t, "\x1b[12;40R\x1b[?62c")
+	d.SetFlushPolicy(printer.FlushManually)
+
+	d.Print("prompt")
+	if _, _, err := d.CurPos(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	// The request can't wait in the buffer.
+	if got := out.String(); !strings.HasPrefix(got, "prompt\x1b[6n") {
+		t.Errorf("err: want the request sent, got (%q)", got)
+	}
+}
+
+func TestMove(t *testing.T) {
+	// Given
+	cases := []struct {
+		move func(d *Display)
+		want string
+	}{
+		{move: func(d *Display) { d.MoveUp(3) }, want: "\x1b[3A"},
+		{move: func(d *Display) { d.MoveDown(1) }, want: "\x1b[1B"},
+		{move: func(d *Display) { d.MoveRight(10) }, want: "\x1b[10C"},
+		{move: func(d *Display) { d.MoveLeft(2) }, want: "\x1b[2D"},
+		{move: func(d *Display) { d.MoveLeft(0) }, want: ""},
+	}
+
+	for _, c := range cases {
+		var out bytes.Buffer
+		d, err := NewDisplayWith(WithOutput(&out), WithoutTermios(), WithEscapes(true))
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		c.move(d)
+		if got := out.String(); got != c.want {
+			t.Errorf("err: want (%q), got (%q)", c.want, got)
+		}
+	}
+}
+
+// countingFile counts the writes (syscalls) to a file.
+type countingFile struct {
+	*os.File
+	writes int
+}
+
+func (f *countingFile) Write(b []byte) (int, error) {
+	f.writes++
+	return f.File.Write(b)
+}
+
+func BenchmarkDisplay(b *testing.B) {
+	// Given
+	cases := []struct {
+		name   string
+		policy printer.FlushPolicy
+	}{
+		{name: "Immediately", policy: printer.FlushImmediately},
+		{name: "Manually", policy: printer.FlushManually},
+	}
+
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			f, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+			if err != nil {
+				b.Fatalf("err: %v", err)
+			}
+			defer f.Close()
+
+			out := &countingFile{File: f}
+			d, err := NewDisplayWith(WithOutput(out), WithoutTermios(), WithEscapes(true), WithFlushPolicy(c.policy))
+			if err != nil {
+				b.Fatalf("err: %v", err)
+			}
+
+			// A frame of 24 lines, in different colours.
+			for range b.N {
+				for y := range 24 {
+					d.MoveTo(1, y+1)
+					d.SetFg(y % 8).Send()
+					d.Print("The quick brown fox jumps over the lazy dog")
+				}
+				d.MoveUp(10)
+				d.Flush()
+			}
+			b.ReportMetric(float64(out.writes)/float64(b.N), "writes/op")
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package termy
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ModeState is the state of a DEC private mode, as reported by the terminal (DECRQM).
+type ModeState int
+
+const (
+	// ModeUnknown is for modes the terminal doesn't recognise (or we didn't ask about).
+	ModeUnknown ModeState = iota
+	ModeSet
+	ModeReset
+	// ModeAlwaysSet is for modes that can't be reset.
+	ModeAlwaysSet
+	// ModeAlwaysReset is for modes that can't be set: recognised, but not supported.
+	ModeAlwaysReset
+)
+
+// Capabilities is what the terminal tells about itself (see Display.Probe).
+type Capabilities struct {
+	// Name and Version come from XTVERSION (i.e. "xterm" and "388").
+	// Many terminals don't answer it, so they may be empty.
+	Name, Version string
+	// Level is the conformance level from the Primary Device Attributes (DA1):
+	// 1 for a VT100, 62 for a VT220, 63 for a VT320, and so on.
+	Level int
+	// Attributes are the features listed by DA1 (i.e. 4 for sixel graphics,
+	// 22 for ANSI colour).
+	Attributes []int
+	// Sixel is set if the terminal can draw sixel graphics.
+	Sixel bool
+	// Type and Firmware come from the Secondary Device Attributes (DA2), i.e.
+	// 41 and 388 for xterm. They are zero if the terminal didn't answer.
+	Type, Firmware int
+	// Modes holds the state of the DEC private modes we asked about (DECRQM).
+	Modes map[int]ModeState
+}
+
+// Supports reports whether the terminal supports the DEC private mode "mode",
+// i.e. 2004 for bracketed paste.
+// Only the modes asked about in Probe are known.
+func (c Capabilities) Supports(mode int) bool {
+	switch c.Modes[mode] {
+	case ModeSet, ModeReset, ModeAlwaysSet:
+		return true
+	}
+	return false
+}
+
+// probedModes are the modes Probe always asks about: the ones termy uses.
+var probedModes = []int{25, 1000, 1002, 1003, 1004, 1006, 1016, 1049, 2004, 2026}
+
+var (
+	// da1Reply is the answer to ESC [ c: ESC [ ? <level> ; <attributes> c.
+	da1Reply = regexp.MustCompile(`^\x1b\[\?([\d;]*)c$`)
+	// da2Reply is the answer to ESC [ > c: ESC [ > <type> ; <firmware> ; <rom> c.
+	da2Reply = regexp.MustCompile(`^\x1b\[>([\d;]*)c$`)
+	// xtversionReply is the answer to ESC [ > 0 q: DCS > | <name and version> ST.
+	xtversionReply = regexp.MustCompile(`^\x1bP>\|(.*)\x1b\\$`)
+)
+
+// Probe asks the terminal what it is and what it supports, and keeps the
+// answer for later (see Capabilities). It asks about the state of the given
+// DEC private modes, on top of the ones termy uses.
+// All the queries go at once, followed by DA1, which every terminal answers:
+// once that reply arrives there's nothing else coming, so the unsupported
+// queries don't make you wait. It fails with ErrNoReply if there's no answer
+// in DefaultQueryTimeout. As with Query, whatever the user types meanwhile is
+// kept.
+func (d *Display) Probe(modes ...int) (Capabilities, error) {
+	defer d.replyMode()()
+
+	modes = append(append([]int{}, probedModes...), modes...)
+
+	var request strings.Builder
+	replies := []*regexp.Regexp{xtversionReply, da2Reply}
+	request.WriteString(_csi + ">0q")
+	request.WriteString(_csi + ">c")
+	for _, mode := range modes {
+		m := strconv.Itoa(mode)
+		request.WriteString(_csi + "?" + m + "$p")
+		replies = append(replies, regexp.MustCompile(`^\x1b\[\?`+m+`;(\d)\$y$`))
+	}
+	// The sentinel.
+	request.WriteString(_csi + "c")
+	replies = append(replies, da1Reply)
+
+	m, err := d.queryAll(request.String(), replies, DefaultQueryTimeout)
+	if err != nil {
+		return Capabilities{}, err
+	}
+
+	caps := Capabilities{Modes: map[int]ModeState{}}
+	if xtversion := m[0]; xtversion != nil {
+		caps.Name, caps.Version = splitVersion(xtversion[1])
+	}
+	if da2 := m[1]; da2 != nil {
+		params := splitParams(da2[1])
+		if len(params) > 1 {
+			caps.Type, caps.Firmware = params[0], params[1]
+		}
+	}
+	for i, mode := range modes {
+		if state := m[2+i]; state != nil {
+			n, _ := strconv.Atoi(state[1])
+			caps.Modes[mode] = ModeState(n)
+		}
+	}
+	if da1 := splitParams(m[len(m)-1][1]); len(da1) > 0 {
+		caps.Level, caps.Attributes = da1[0], da1[1:]
+		for _, a := range caps.Attributes {
+			if a == 4 {
+				caps.Sixel = true
+			}
+		}
+	}
+
+	d.mu.Lock()
+	d.caps = caps
+	d.mu.Unlock()
+
+	return caps, nil
+}
+
+// Capabilities returns what the last Probe found out, or the zero value if
+// there was none.
+func (d *Display) Capabilities() Capabilities {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.caps
+}
+
+// -------- Internal -------- //
+
+// splitParams parses a list of numbers separated by ';'.
+func splitParams(s string) []int {
+	params := []int{}
+	for _, p := range strings.Split(s, ";") {
+		if n, err := strconv.Atoi(p); err == nil {
+			params = append(params, n)
+		}
+	}
+	return params
+}
+
+// splitVersion splits the XTVERSION reply: most terminals send
+// "name(version)" (i.e. "xterm(388)"), some "name version".
+func splitVersion(s string) (name, version string) {
+	if i := strings.IndexByte(s, '('); i > 0 && strings.HasSuffix(s, ")") {
+		return s[:i], s[i+1 : len(s)-1]
+	}
+	name, version, _ = strings.Cut(s, " ")
+	return name, version
+}
@@ -0,0 +1,106 @@
+package termy
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mec-nyan/termy/input"
+)
+
+func TestProbe(t *testing.T) {
+	// Given
+	cases := []struct {
+		name    string
+		replies string
+		want    Capabilities
+	}{
+		{
+			name: "Everything",
+			replies: "\x1bP>|xterm(388)\x1b\\" +
+				"\x1b[>41;388;0c" +
+				"\x1b[?25;1$y\x1b[?1000;2$y\x1b[?1002;2$y\x1b[?1003;2$y\x1b[?1004;2$y\x1b[?1006;2$y" +
+				"\x1b[?1016;0$y\x1b[?1049;2$y\x1b[?2004;2$y\x1b[?2026;4$y\x1b[?7;3$y" +
+				"\x1b[?65;1;4;22c",
+			want: Capabilities{
+				Name: "xterm", Version: "388",
+				Level: 65, Attributes: []int{1, 4, 22}, Sixel: true,
+				Type: 41, Firmware: 388,
+				Modes: map[int]ModeState{
+					25: ModeSet, 1000: ModeReset, 1002: ModeReset, 1003: ModeReset, 1004: ModeReset,
+					1006: ModeReset, 1016: ModeUnknown, 1049: ModeReset, 2004: ModeReset,
+					2026: ModeAlwaysReset, 7: ModeAlwaysSet,
+				},
+			},
+		},
+		{
+			name:    "Only DA1",
+			replies: "\x1b[?1;2c",
+			want:    Capabilities{Level: 1, Attributes: []int{2}, Modes: map[int]ModeState{}},
+		},
+		{
+			name:    "Name and version with a space",
+			replies: "\x1bP>|WezTerm 20240203\x1b\\\x1b[?62;22c",
+			want:    Capabilities{Name: "WezTerm", Version: "20240203", Level: 62, Attributes: []int{22}, Modes: map[int]ModeState{}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out bytes.Buffer
+			d, err := NewDisplayWith(WithInput(strings.NewReader(c.replies)), WithOutput(&out), WithoutTermios(), WithEscapes(true))
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+
+			got, err := d.Probe(7)
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("err: want (%+v), got (%+v)", c.want, got)
+			}
+			if !reflect.DeepEqual(d.Capabilities(), got) {
+				t.Errorf("err: not kept: (%+v)", d.Capabilities())
+			}
+
+			// Everything in one go, with DA1 last.
+			request := out.String()
+			if !strings.HasPrefix(request, "\x1b[>0q\x1b[>c\x1b[?25$p") || !strings.HasSuffix(request, "\x1b[?7$p\x1b[c") {
+				t.Errorf("err: unexpected request (%q)", request)
+			}
+		})
+	}
+}
+
+func TestProbeKeepsInput(t *testing.T) {
+	var out bytes.Buffer
+	d, err := NewDisplayWith(
+		WithInput(strings.NewReader("a\x1b[?2004;1$yb\x1b[?62c")),
+		WithOutput(&out),
+		WithoutTermios(),
+		WithEscapes(true),
+	)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	caps, err := d.Probe()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !caps.Supports(2004) || caps.Supports(2026) || caps.Supports(7) {
+		t.Errorf("err: unexpected modes (%v)", caps.Modes)
+	}
+
+	for _, want := range []rune{'a', 'b'} {
+		got, err := d.ReadEvent()
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if got != (input.Key{Code: input.KeyRune, Rune: want}) {
+			t.Errorf("err: want (%c), got (%v)", want, got)
+		}
+	}
+}
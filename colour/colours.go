@@ -4,6 +4,7 @@ package colour
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 const (
@@ -31,6 +32,20 @@ const (
 type Colour struct {
 	// fg and bg are private, so we don't overwrite them by mistake.
 	fg, bg string
+	// profile limits the colours we can use.
+	profile Profile
+}
+
+// SetProfile sets the colour depth supported by the terminal.
+// Colours set afterwards will be approximated to fit in it.
+func (c *Colour) SetProfile(p Profile) *Colour {
+	c.profile = p
+	return c
+}
+
+// Profile returns the selected colour depth.
+func (c *Colour) Profile() Profile {
+	return c.profile
 }
 
 // Fg returns the currently set fg sequence.
@@ -55,6 +70,16 @@ func (c *Colour) Code() string {
 	return c.fg + ";" + c.bg
 }
 
+// Convert returns the colour approximated to the profile p, i.e. the one of
+// the terminal you're sending it to. A colour set with a smaller profile
+// stays as it is: it can't be made richer.
+func (c *Colour) Convert(p Profile) Colour {
+	out := Colour{profile: p}
+	out.fg = out.convert(fgLayer, c.fg)
+	out.bg = out.convert(bgLayer, c.bg)
+	return out
+}
+
 // Reset empty the sequences. It will produce no changes beyond cleaning its state.
 // If you wish to use the default colours, use UseDefault instead.
 func (c *Colour) Reset() *Colour {
@@ -86,13 +111,13 @@ func (c *Colour) UseDefault() *Colour {
 
 // UseDefaultFg sets the default fg.
 func (c *Colour) UseDefaultFg() *Colour {
-	c.fg = "39"
+	c.fg = c.defaultCode(fgLayer)
 	return c
 }
 
 // UseDefaultBg sets the default bg.
 func (c *Colour) UseDefaultBg() *Colour {
-	c.bg = "49"
+	c.bg = c.defaultCode(bgLayer)
 	return c
 }
 
@@ -104,7 +129,7 @@ func (c *Colour) SetFg(colour int) *Colour {
 		c.UseDefaultFg()
 		return c
 	}
-	c.fg = fmt.Sprintf("38:5:%d", colour)
+	c.fg = c.indexedCode(fgLayer, colour)
 	return c
 }
 
@@ -116,7 +141,7 @@ func (c *Colour) SetBg(colour int) *Colour {
 		c.UseDefaultBg()
 		return c
 	}
-	c.bg = fmt.Sprintf("48:5:%d", colour)
+	c.bg = c.indexedCode(bgLayer, colour)
 	return c
 }
 
@@ -127,7 +152,7 @@ func (c *Colour) SetFgRGB(r, g, b int) *Colour {
 		c.UseDefaultFg()
 		return c
 	}
-	c.fg = fmt.Sprintf("38:2:%d:%d:%d", r, g, b)
+	c.fg = c.rgbCode(fgLayer, r, g, b)
 	return c
 }
 
@@ -138,7 +163,7 @@ func (c *Colour) SetBgRGB(r, g, b int) *Colour {
 		c.UseDefaultBg()
 		return c
 	}
-	c.bg = fmt.Sprintf("48:2:%d:%d:%d", r, g, b)
+	c.bg = c.rgbCode(bgLayer, r, g, b)
 	return c
 }
 
@@ -171,6 +196,26 @@ func fromHex(hex string) (r, g, b int) {
 
 // Internal.
 
+// convert encodes the code of the layer l again, with c's profile.
+func (c *Colour) convert(l layer, code string) string {
+	if code == "" || c.profile == NoColour {
+		return ""
+	}
+	parts := strings.Split(code, ":")
+	n := make([]int, len(parts))
+	for i, part := range parts {
+		n[i], _ = strconv.Atoi(part)
+	}
+	switch {
+	case len(n) == 3 && n[0] == l.extended && n[1] == 5:
+		return c.indexedCode(l, n[2])
+	case len(n) == 5 && n[0] == l.extended && n[1] == 2:
+		return c.rgbCode(l, n[2], n[3], n[4])
+	}
+	// The basic colours and the default are in every profile.
+	return code
+}
+
 // hexToRGB tries to parse a string containing an hex colour.
 func hexToRGB(colour string) (r, g, b int, err error) {
 	colour, err = getHexColour(colour)
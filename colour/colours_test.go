@@ -208,3 +208,161 @@ func TestColours(t *testing.T) {
 		})
 	}
 }
+
+func TestProfiles(t *testing.T) {
+	// Given
+	cases := []struct {
+		name    string
+		profile Profile
+		want    string
+		action  func(*Colour) string
+	}{
+		{
+			name:    "TrueColour rgb",
+			profile: TrueColour,
+			want:    "38:2:255:0:0",
+			action: func(c *Colour) string {
+				c.SetFgRGB(255, 0, 0)
+				return c.Code()
+			},
+		},
+		{
+			name:    "ANSI256 rgb (cube)",
+			profile: ANSI256,
+			want:    "38:5:196",
+			action: func(c *Colour) string {
+				c.SetFgRGB(255, 0, 0)
+				return c.Code()
+			},
+		},
+		{
+			name:    "ANSI256 rgb (grey)",
+			profile: ANSI256,
+			want:    "48:5:244",
+			action: func(c *Colour) string {
+				c.SetBgRGB(128, 128, 128)
+				return c.Code()
+			},
+		},
+		{
+			name:    "ANSI256 indexed",
+			profile: ANSI256,
+			want:    "38:5:212",
+			action: func(c *Colour) string {
+				c.SetFg(212)
+				return c.Code()
+			},
+		},
+		{
+			name:    "ANSI16 basic",
+			profile: ANSI16,
+			want:    "31;44",
+			action: func(c *Colour) string {
+				c.SetFg(Red)
+				c.SetBg(Blue)
+				return c.Code()
+			},
+		},
+		{
+			name:    "ANSI16 bright",
+			profile: ANSI16,
+			want:    "93;107",
+			action: func(c *Colour) string {
+				c.SetFg(BrightYellow)
+				c.SetBg(BrightWhite)
+				return c.Code()
+			},
+		},
+		{
+			name:    "ANSI16 indexed",
+			profile: ANSI16,
+			want:    "91",
+			action: func(c *Colour) string {
+				c.SetFg(196)
+				return c.Code()
+			},
+		},
+		{
+			name:    "ANSI16 hex",
+			profile: ANSI16,
+			want:    "32",
+			action: func(c *Colour) string {
+				c.SetFgHex("#00C000")
+				return c.Code()
+			},
+		},
+		{
+			name:    "ANSI16 default",
+			profile: ANSI16,
+			want:    "39;49",
+			action: func(c *Colour) string {
+				c.UseDefault()
+				return c.Code()
+			},
+		},
+		{
+			name:    "NoColour",
+			profile: NoColour,
+			want:    "",
+			action: func(c *Colour) string {
+				c.SetFgRGB(255, 0, 0)
+				c.SetBg(Red)
+				return c.Code()
+			},
+		},
+		{
+			name:    "NoColour default",
+			profile: NoColour,
+			want:    "",
+			action: func(c *Colour) string {
+				c.UseDefault()
+				return c.Code()
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			colour := Colour{}
+			colour.SetProfile(c.profile)
+			got := c.action(&colour)
+			if got != c.want {
+				t.Errorf("err: want (%s), got (%s)", c.want, got)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	var c Colour
+	c.SetFgRGB(255, 0, 0).SetBg(196)
+
+	// Given
+	cases := []struct {
+		profile Profile
+		want    string
+	}{
+		{profile: TrueColour, want: "38:2:255:0:0;48:5:196"},
+		{profile: ANSI256, want: "38:5:196;48:5:196"},
+		{profile: ANSI16, want: "91;101"},
+		{profile: NoColour, want: ""},
+	}
+
+	for _, tc := range cases {
+		got := c.Convert(tc.profile)
+		if code := got.Code(); code != tc.want {
+			t.Errorf("err: %v: want (%s), got (%s)", tc.profile, tc.want, code)
+		}
+		if got.Profile() != tc.profile {
+			t.Errorf("err: want the profile (%v), got (%v)", tc.profile, got.Profile())
+		}
+	}
+
+	// The basic colours stay as they are, and can't be made richer.
+	var basic Colour
+	basic.SetProfile(ANSI16).SetFg(Red).UseDefaultBg()
+	got := basic.Convert(TrueColour)
+	if code := got.Code(); code != "31;49" {
+		t.Errorf("err: want (31;49), got (%s)", code)
+	}
+}
@@ -0,0 +1,174 @@
+package colour
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Profile is the colour depth supported by a terminal.
+// Colours beyond the selected profile are approximated to the nearest supported one.
+type Profile int
+
+const (
+	// TrueColour supports 24 bit colours. This is the default.
+	TrueColour Profile = iota
+	// ANSI256 supports the 256 colours palette.
+	ANSI256
+	// ANSI16 supports the 16 basic colours only.
+	ANSI16
+	// NoColour doesn't support colours at all. No sequences are generated.
+	NoColour
+)
+
+func (p Profile) String() string {
+	switch p {
+	case TrueColour:
+		return "TrueColour"
+	case ANSI256:
+		return "ANSI256"
+	case ANSI16:
+		return "ANSI16"
+	case NoColour:
+		return "NoColour"
+	}
+	return "Profile(" + strconv.Itoa(int(p)) + ")"
+}
+
+// Valid reports whether p is one of the known profiles.
+func (p Profile) Valid() bool {
+	return p >= TrueColour && p <= NoColour
+}
+
+// Internal.
+
+// layer holds the codes used for either the foreground or the background.
+type layer struct {
+	base, bright, extended, def int
+}
+
+var (
+	fgLayer = layer{base: 30, bright: 90, extended: 38, def: 39}
+	bgLayer = layer{base: 40, bright: 100, extended: 48, def: 49}
+)
+
+// defaultCode returns the code for the default colour of the layer.
+func (c *Colour) defaultCode(l layer) string {
+	if c.profile == NoColour {
+		return ""
+	}
+	return strconv.Itoa(l.def)
+}
+
+// indexedCode returns the code for the colour n (0-255) of the palette.
+func (c *Colour) indexedCode(l layer, n int) string {
+	switch c.profile {
+	case NoColour:
+		return ""
+	case ANSI16:
+		n = to16(n)
+		if n < 8 {
+			return strconv.Itoa(l.base + n)
+		}
+		return strconv.Itoa(l.bright + n - 8)
+	}
+	return fmt.Sprintf("%d:5:%d", l.extended, n)
+}
+
+// rgbCode returns the code for a 24 bit colour.
+func (c *Colour) rgbCode(l layer, r, g, b int) string {
+	switch c.profile {
+	case NoColour:
+		return ""
+	case ANSI256:
+		return c.indexedCode(l, rgbTo256(r, g, b))
+	case ANSI16:
+		return c.indexedCode(l, rgbTo16(r, g, b))
+	}
+	return fmt.Sprintf("%d:2:%d:%d:%d", l.extended, r, g, b)
+}
+
+// ansi16 holds the rgb values xterm uses for the 16 basic colours.
+var ansi16 = [16][3]int{
+	{0x00, 0x00, 0x00}, {0xcd, 0x00, 0x00}, {0x00, 0xcd, 0x00}, {0xcd, 0xcd, 0x00},
+	{0x00, 0x00, 0xee}, {0xcd, 0x00, 0xcd}, {0x00, 0xcd, 0xcd}, {0xe5, 0xe5, 0xe5},
+	{0x7f, 0x7f, 0x7f}, {0xff, 0x00, 0x00}, {0x00, 0xff, 0x00}, {0xff, 0xff, 0x00},
+	{0x5c, 0x5c, 0xff}, {0xff, 0x00, 0xff}, {0x00, 0xff, 0xff}, {0xff, 0xff, 0xff},
+}
+
+// cubeLevels are the values each channel takes in the 6x6x6 colour cube (16-231).
+var cubeLevels = [6]int{0x00, 0x5f, 0x87, 0xaf, 0xd7, 0xff}
+
+// paletteRGB returns the rgb values of the colour n (0-255) of the xterm palette.
+func paletteRGB(n int) (r, g, b int) {
+	switch {
+	case n < 16:
+		return ansi16[n][0], ansi16[n][1], ansi16[n][2]
+	case n < 232:
+		n -= 16
+		return cubeLevels[n/36], cubeLevels[n/6%6], cubeLevels[n%6]
+	}
+	grey := 8 + (n-232)*10
+	return grey, grey, grey
+}
+
+// to16 approximates the colour n (0-255) to one of the 16 basic colours.
+func to16(n int) int {
+	if n < 16 {
+		return n
+	}
+	return rgbTo16(paletteRGB(n))
+}
+
+// rgbTo16 approximates a 24 bit colour to one of the 16 basic colours.
+func rgbTo16(r, g, b int) int {
+	best, bestDist := 0, -1
+	for i, c := range ansi16 {
+		d := distance(r, g, b, c[0], c[1], c[2])
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// rgbTo256 approximates a 24 bit colour to the nearest one in the colour cube or the grey ramp.
+func rgbTo256(r, g, b int) int {
+	ri, gi, bi := nearestLevel(r), nearestLevel(g), nearestLevel(b)
+	cube := 16 + 36*ri + 6*gi + bi
+	cubeDist := distance(r, g, b, cubeLevels[ri], cubeLevels[gi], cubeLevels[bi])
+
+	// Grey ramp: 232 (8) to 255 (238) in steps of 10.
+	avg := (r + g + b) / 3
+	gi = (avg - 3) / 10
+	gi = max(0, min(23, gi))
+	grey := 8 + gi*10
+	greyDist := distance(r, g, b, grey, grey, grey)
+
+	if greyDist < cubeDist {
+		return 232 + gi
+	}
+	return cube
+}
+
+// nearestLevel returns the index of the cube level closest to v.
+func nearestLevel(v int) int {
+	best := 0
+	for i, l := range cubeLevels {
+		if abs(v-l) < abs(v-cubeLevels[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+func distance(r1, g1, b1, r2, g2, b2 int) int {
+	dr, dg, db := r1-r2, g1-g2, b1-b2
+	return dr*dr + dg*dg + db*db
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,58 @@
+package colour
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RGB is a colour as red, green and blue values (0-255).
+type RGB struct {
+	R, G, B int
+}
+
+// Luminance returns how bright the colour looks, from 0 (black) to 1 (white).
+func (c RGB) Luminance() float64 {
+	return (0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)) / 255
+}
+
+// IsDark reports whether light text reads better than dark text on top of the colour.
+func (c RGB) IsDark() bool {
+	return c.Luminance() < 0.5
+}
+
+// Hex returns the colour as "#RRGGBB", the format SetFgHex and SetBgHex take.
+func (c RGB) Hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// ParseXColour parses a colour in the format used by X11, which is how
+// terminals report them: "rgb:R/G/B", with 1 to 4 hex digits per value
+// (i.e. "rgb:ffff/8080/0000"). The alpha in "rgba:R/G/B/A" is ignored.
+func ParseXColour(spec string) (RGB, error) {
+	values, found := strings.CutPrefix(spec, "rgb:")
+	want := 3
+	if rest, ok := strings.CutPrefix(spec, "rgba:"); ok {
+		values, found, want = rest, true, 4
+	}
+
+	parts := strings.Split(values, "/")
+	if !found || len(parts) != want {
+		return RGB{}, fmt.Errorf("err: '%s' is not a valid X11 colour", spec)
+	}
+
+	var rgb [3]int
+	for i := range rgb {
+		p := parts[i]
+		if len(p) == 0 || len(p) > 4 || !isHexNum(p) {
+			return RGB{}, fmt.Errorf("err: '%s' is not a valid X11 colour", spec)
+		}
+		// ParseUint can't fail, we've checked the digits already.
+		v, _ := strconv.ParseUint(p, 16, 16)
+		// Scale it to 8 bits: "f", "ff" and "ffff" are all 255.
+		max := uint64(1)<<(4*len(p)) - 1
+		rgb[i] = int((v*255 + max/2) / max)
+	}
+
+	return RGB{R: rgb[0], G: rgb[1], B: rgb[2]}, nil
+}
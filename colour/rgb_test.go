@@ -0,0 +1,56 @@
+package colour
+
+import "testing"
+
+func TestParseXColour(t *testing.T) {
+	// Given
+	cases := []struct {
+		spec string
+		want RGB
+	}{
+		{spec: "rgb:0000/0000/0000", want: RGB{0, 0, 0}},
+		{spec: "rgb:ffff/ffff/ffff", want: RGB{255, 255, 255}},
+		{spec: "rgb:1e1e/1e1e/2e2e", want: RGB{30, 30, 46}},
+		{spec: "rgb:ff/80/00", want: RGB{255, 128, 0}},
+		{spec: "rgb:f/8/0", want: RGB{255, 136, 0}},
+		{spec: "rgb:fff/800/000", want: RGB{255, 128, 0}},
+		{spec: "rgba:ffff/0000/0000/ffff", want: RGB{255, 0, 0}},
+	}
+
+	for _, c := range cases {
+		got, err := ParseXColour(c.spec)
+		if err != nil {
+			t.Errorf("err: %s: %v", c.spec, err)
+		}
+		if got != c.want {
+			t.Errorf("err: %s: want (%v), got (%v)", c.spec, c.want, got)
+		}
+	}
+
+	for _, spec := range []string{"", "#ffffff", "rgb:", "rgb:ff/ff", "rgb:ff/ff/ff/ff", "rgb:fffff/0/0", "rgb:gg/00/00", "rgba:f/f/f"} {
+		if got, err := ParseXColour(spec); err == nil {
+			t.Errorf("err: %q: want an error, got (%v)", spec, got)
+		}
+	}
+}
+
+func TestIsDark(t *testing.T) {
+	// Given
+	cases := []struct {
+		rgb  RGB
+		want bool
+	}{
+		{rgb: RGB{0, 0, 0}, want: true},
+		{rgb: RGB{30, 30, 46}, want: true},
+		{rgb: RGB{0, 0, 255}, want: true},
+		{rgb: RGB{255, 255, 255}, want: false},
+		{rgb: RGB{253, 246, 227}, want: false},
+		{rgb: RGB{255, 255, 0}, want: false},
+	}
+
+	for _, c := range cases {
+		if got := c.rgb.IsDark(); got != c.want {
+			t.Errorf("err: %v: want (%v), got (%v)", c.rgb, c.want, got)
+		}
+	}
+}
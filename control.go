@@ -64,27 +64,19 @@ func Left() {
 }
 
 func MoveUp(lines int) {
-	for i := 0; i < lines; i++ {
-		Up()
-	}
+	move(lines, 'A')
 }
 
 func MoveDown(lines int) {
-	for i := 0; i < lines; i++ {
-		Down()
-	}
+	move(lines, 'B')
 }
 
 func MoveRight(lines int) {
-	for i := 0; i < lines; i++ {
-		Right()
-	}
+	move(lines, 'C')
 }
 
 func MoveLeft(lines int) {
-	for i := 0; i < lines; i++ {
-		Left()
-	}
+	move(lines, 'D')
 }
 
 func MoveTo(row, col int) {
@@ -119,6 +111,13 @@ func writeBytes(b ...byte) {
 	os.Stdout.Write(b)
 }
 
+func move(n int, dir byte) {
+	if n <= 0 {
+		return
+	}
+	writeBytes(csi(append(intToBytes(n), dir)...)...)
+}
+
 func escape(b ...byte) []byte {
 	return append([]byte{'\x1b'}, b...)
 }
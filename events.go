@@ -0,0 +1,253 @@
+package termy
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mec-nyan/termy/input"
+	"github.com/mec-nyan/termy/term"
+	"github.com/mec-nyan/termy/tty"
+)
+
+const (
+	// postQueue is how many posted events can wait to be delivered (see Post).
+	postQueue = 64
+	// eventsResizeDelay collapses bursts of resizes (see NotifyResize).
+	eventsResizeDelay = 50 * time.Millisecond
+)
+
+// eventLoop is the state shared by a running Events loop and Post.
+type eventLoop struct {
+	posts chan input.Event
+	// stopped is closed when the loop no longer takes events, and finished
+	// once it's completely done.
+	stopped, finished chan struct{}
+}
+
+// ReadEvent reads the next event (i.e. a key press) from the Display's input,
+// blocking until there is one.
+// You'll want the terminal UnCookIt (or MakeRaw) first, otherwise keys only
+// arrive after Enter is pressed.
+// Don't use it while Events is running.
+func (d *Display) ReadEvent() (input.Event, error) {
+	if ev, ok := d.nextPending(); ok {
+		return ev, nil
+	}
+	// Show everything before waiting for the user.
+	d.Flush()
+	return d.input().ReadEvent()
+}
+
+// SetEscTimeout sets how long to wait for the rest of a sequence before
+// reading a lone ESC as Escape, instead of Alt plus the next key.
+// The default is input.DefaultEscTimeout. It only applies when the input is a
+// file (i.e. a terminal): otherwise there's no telling, and the next byte decides.
+func (d *Display) SetEscTimeout(timeout time.Duration) *Display {
+	d.input().SetEscTimeout(timeout)
+	return d
+}
+
+// Events delivers everything your program needs to react to on a single
+// channel: the user input (keys, mouse, paste and focus events), resizes
+// (as input.Resize) and your own events (see Post).
+//
+// The channel is closed when ctx is done or the input fails (i.e. io.EOF),
+// and then EventsErr tells why. Only one loop can run at a time: starting a
+// new one waits for the previous one to finish.
+//
+// Ordering: the events from each source arrive in order (the input as it
+// was typed, your events as they were posted), but there's no order between
+// sources. Resizes are collapsed: you only get the latest size.
+//
+// Output: with a FlushPolicy other than printer.FlushImmediately, Flush once
+// you've drawn what the event asked for.
+//
+// Back-pressure: nothing is read ahead. While you don't receive, the input
+// waits in the terminal, and Post fails once its queue is full.
+//
+// When ctx is done, the events read but not delivered yet are kept for the
+// next loop, so no key is lost. If the input is a file (i.e. a terminal), the
+// loop stops reading straight away. Otherwise a read in progress can't be
+// interrupted: it carries on until some input arrives, and starting the next
+// loop waits until then.
+func (d *Display) Events(ctx context.Context) (<-chan input.Event, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for d.loop != nil {
+		l := d.loop
+		select {
+		case <-l.stopped:
+		default:
+			return nil, errors.New("err: the event loop is already running")
+		}
+		// It's on its way out, wait for it.
+		d.mu.Unlock()
+		<-l.finished
+		d.mu.Lock()
+	}
+
+	d.loop = &eventLoop{
+		posts:    make(chan input.Event, postQueue),
+		stopped:  make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+	d.eventsErr = nil
+
+	out := make(chan input.Event)
+	go d.runEvents(ctx, d.loop, out)
+
+	return out, nil
+}
+
+// EventsErr returns why the last Events loop stopped: the context's error or
+// the input's. It's nil while the loop is running.
+func (d *Display) EventsErr() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.eventsErr
+}
+
+// Post delivers v through Events, as input.Custom. It's safe to call from any
+// goroutine, and it never blocks: it reports false if there's no loop running
+// or too many events are waiting already.
+// Events still waiting when the loop stops are dropped.
+func (d *Display) Post(v any) bool {
+	d.mu.Lock()
+	l := d.loop
+	d.mu.Unlock()
+
+	if l == nil {
+		return false
+	}
+	select {
+	case <-l.stopped:
+		return false
+	default:
+	}
+
+	select {
+	case l.posts <- input.Custom{Value: v}:
+		return true
+	default:
+		return false
+	}
+}
+
+// -------- Internal -------- //
+
+// input returns the reader for the Display's input, creating it if needed.
+// It's safe to call from several goroutines (i.e. a query and the event loop).
+func (d *Display) input() *input.Reader {
+	d.inOnce.Do(func() {
+		d.in = input.NewReader(d.Stdin)
+		// With a file we can wait for input, so a lone ESC doesn't wait
+		// for the next key and reads can be cancelled.
+		if fd, ok := tty.Fd(d.Stdin); ok {
+			d.in.SetWaiter(term.New(fd))
+		}
+	})
+	return d.in
+}
+
+// runEvents merges the events into "out" until ctx is done or the input fails.
+func (d *Display) runEvents(ctx context.Context, l *eventLoop, out chan<- input.Event) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	d.mu.Lock()
+	pending := d.pending
+	d.pending = nil
+	d.mu.Unlock()
+
+	// The reader hands over one event at a time, so nothing is read ahead.
+	keys := make(chan input.Event)
+	readDone := make(chan struct{})
+	var (
+		readErr error
+		// unsent is the event the reader had when the loop stopped.
+		unsent input.Event
+	)
+	go func() {
+		defer close(readDone)
+		for {
+			var ev input.Event
+			if len(pending) > 0 {
+				ev, pending = pending[0], pending[1:]
+			} else {
+				var err error
+				if ev, err = d.input().ReadEventContext(ctx); err != nil {
+					readErr = err
+					return
+				}
+			}
+			select {
+			case keys <- ev:
+			case <-ctx.Done():
+				unsent = ev
+				return
+			}
+		}
+	}()
+
+	// Without the terminal settings there's no size to report.
+	var sizes <-chan Size
+	if !d.noTermios {
+		var stopResize func()
+		sizes, stopResize = d.NotifyResize(eventsResizeDelay)
+		defer stopResize()
+	}
+
+	var (
+		err error
+		// held is the event we were delivering when the loop stopped.
+		held input.Event
+	)
+loop:
+	for {
+		var ev input.Event
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			break loop
+		case <-readDone:
+			err = readErr
+			break loop
+		case ev = <-keys:
+		case size := <-sizes:
+			ev = input.Resize{Rows: size.Rows, Cols: size.Cols}
+		case ev = <-l.posts:
+		}
+
+		select {
+		case out <- ev:
+		case <-ctx.Done():
+			err = ctx.Err()
+			held = ev
+			break loop
+		}
+	}
+	close(l.stopped)
+
+	d.mu.Lock()
+	d.eventsErr = err
+	d.mu.Unlock()
+	close(out)
+
+	// Wait for the reader, so the next loop doesn't read at the same time.
+	cancel()
+	<-readDone
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, ev := range []input.Event{held, unsent} {
+		if ev != nil {
+			d.pending = append(d.pending, ev)
+		}
+	}
+	d.pending = append(d.pending, pending...)
+	d.loop = nil
+	close(l.finished)
+}
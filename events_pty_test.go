@@ -0,0 +1,123 @@
+//go:build linux
+
+package termy
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mec-nyan/termy/input"
+	"github.com/mec-nyan/termy/internal/pty"
+	"golang.org/x/sys/unix"
+)
+
+func TestEventsTerminal(t *testing.T) {
+	master, slave, err := pty.Open()
+	if err != nil {
+		t.Skipf("can't open a pty: %v", err)
+	}
+	defer master.Close()
+	defer slave.Close()
+
+	pty.SetSize(master, 24, 80)
+	var out bytes.Buffer
+	d, err := NewDisplayWith(WithInput(slave), WithOutput(&out), WithFd(int(slave.Fd())))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := d.MakeRaw(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := d.Events(ctx)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	master.Write([]byte("\x1b[B"))
+	if got, _ := receive(t, events); got != (input.Key{Code: input.KeyDown}) {
+		t.Errorf("err: want (Down), got (%v)", got)
+	}
+
+	pty.SetSize(master, 30, 100)
+	unix.Kill(os.Getpid(), unix.SIGWINCH)
+	if got, _ := receive(t, events); got != (input.Resize{Rows: 30, Cols: 100}) {
+		t.Errorf("err: want (30x100), got (%v)", got)
+	}
+
+	// The reader is waiting on the terminal: cancelling stops it for good.
+	cancel()
+	if ev, ok := receive(t, events); ok {
+		t.Errorf("err: want the channel closed, got (%v)", ev)
+	}
+
+	started := make(chan struct{})
+	go func() {
+		defer close(started)
+		events, err = d.Events(context.Background())
+	}()
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("err: the previous loop is still reading")
+	}
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	master.Write([]byte("x"))
+	if got, _ := receive(t, events); got != (input.Key{Code: input.KeyRune, Rune: 'x'}) {
+		t.Errorf("err: want (x), got (%v)", got)
+	}
+}
+
+func TestQueryTerminal(t *testing.T) {
+	master, slave, err := pty.Open()
+	if err != nil {
+		t.Skipf("can't open a pty: %v", err)
+	}
+	defer master.Close()
+	defer slave.Close()
+
+	var out bytes.Buffer
+	d, err := NewDisplayWith(WithInput(slave), WithOutput(&out), WithFd(int(slave.Fd())), WithEscapes(true))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := d.MakeRaw(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// No answer: give up in time.
+	start := time.Now()
+	if _, err := d.Query("\x1b[c", cprReply, 100*time.Millisecond); err != ErrNoReply {
+		t.Errorf("err: want (%v), got (%v)", ErrNoReply, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("err: took too long to give up (%v)", elapsed)
+	}
+
+	// With the event loop running, the keys go on through it.
+	events, err := d.Events(context.Background())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		master.Write([]byte("q\x1b[7;3R"))
+	}()
+	x, y, err := d.CurPos()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if x != 3 || y != 7 {
+		t.Errorf("err: want (3, 7), got (%d, %d)", x, y)
+	}
+	if got, _ := receive(t, events); got != (input.Key{Code: input.KeyRune, Rune: 'q'}) {
+		t.Errorf("err: want (q), got (%v)", got)
+	}
+}
@@ -0,0 +1,202 @@
+package termy
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mec-nyan/termy/input"
+)
+
+func TestReadEvent(t *testing.T) {
+	var out bytes.Buffer
+	d, err := NewDisplayWith(WithInput(strings.NewReader("q\x1b[A")), WithOutput(&out), WithoutTermios())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	want := []input.Event{
+		input.Key{Code: input.KeyRune, Rune: 'q'},
+		input.Key{Code: input.KeyUp},
+	}
+	for _, w := range want {
+		got, err := d.ReadEvent()
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if got != w {
+			t.Errorf("err: want (%v), got (%v)", w, got)
+		}
+	}
+
+	if _, err := d.ReadEvent(); err != io.EOF {
+		t.Errorf("err: want (EOF), got (%v)", err)
+	}
+}
+
+// receive gets the next event, or fails after a while.
+func receive(t *testing.T, events <-chan input.Event) (input.Event, bool) {
+	t.Helper()
+
+	select {
+	case ev, ok := <-events:
+		return ev, ok
+	case <-time.After(2 * time.Second):
+		t.Fatal("err: no event")
+		return nil, false
+	}
+}
+
+func TestEvents(t *testing.T) {
+	var out bytes.Buffer
+	d, err := NewDisplayWith(WithInput(strings.NewReader("ab\x1b[A\x1b[I")), WithOutput(&out), WithoutTermios())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	events, err := d.Events(context.Background())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, err := d.Events(context.Background()); err == nil {
+		t.Error("err: want an error starting a second loop, got nil")
+	}
+
+	// The input arrives in order.
+	want := []input.Event{
+		input.Key{Code: input.KeyRune, Rune: 'a'},
+		input.Key{Code: input.KeyRune, Rune: 'b'},
+		input.Key{Code: input.KeyUp},
+		input.FocusGained{},
+	}
+	for _, w := range want {
+		got, _ := receive(t, events)
+		if got != w {
+			t.Errorf("err: want (%v), got (%v)", w, got)
+		}
+	}
+
+	// Then the channel is closed, telling why.
+	if ev, ok := receive(t, events); ok {
+		t.Errorf("err: want the channel closed, got (%v)", ev)
+	}
+	if err := d.EventsErr(); err != io.EOF {
+		t.Errorf("err: want (EOF), got (%v)", err)
+	}
+	if d.Post(1) {
+		t.Error("err: posted with no loop running")
+	}
+}
+
+func TestEventsPost(t *testing.T) {
+	var out bytes.Buffer
+	// No input at all, so we only get what we post.
+	r, w := io.Pipe()
+	defer w.Close()
+	d, err := NewDisplayWith(WithInput(r), WithOutput(&out), WithoutTermios())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := d.Events(ctx)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Without receiving, the queue fills up and Post fails.
+	posted := 0
+	for d.Post(posted) {
+		posted++
+		if posted > 2*postQueue {
+			t.Fatal("err: Post never fails")
+		}
+	}
+	if posted < postQueue {
+		t.Errorf("err: want at least (%d) posted, got (%d)", postQueue, posted)
+	}
+
+	// Everything posted arrives, in order.
+	for i := range posted {
+		got, _ := receive(t, events)
+		if want := (input.Custom{Value: i}); got != want {
+			t.Fatalf("err: want (%v), got (%v)", want, got)
+		}
+	}
+	if !d.Post("more") {
+		t.Error("err: can't post after receiving")
+	}
+	if got, _ := receive(t, events); got != (input.Custom{Value: "more"}) {
+		t.Errorf("err: want (more), got (%v)", got)
+	}
+
+	cancel()
+	if ev, ok := receive(t, events); ok {
+		t.Errorf("err: want the channel closed, got (%v)", ev)
+	}
+	if err := d.EventsErr(); err != context.Canceled {
+		t.Errorf("err: want (%v), got (%v)", context.Canceled, err)
+	}
+}
+
+func TestEventsKeepUndelivered(t *testing.T) {
+	var out bytes.Buffer
+	d, err := NewDisplayWith(WithInput(strings.NewReader("abc")), WithOutput(&out), WithoutTermios())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := d.Events(ctx)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if got, _ := receive(t, events); got != (input.Key{Code: input.KeyRune, Rune: 'a'}) {
+		t.Errorf("err: want (a), got (%v)", got)
+	}
+	// Give the loop time to get ahead of us.
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	for range events {
+	}
+
+	// The next loop carries on where the last one stopped.
+	events, err = d.Events(context.Background())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	got := []input.Event{}
+	for ev := range events {
+		got = append(got, ev)
+	}
+	want := []input.Event{
+		input.Key{Code: input.KeyRune, Rune: 'b'},
+		input.Key{Code: input.KeyRune, Rune: 'c'},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("err: want (%v), got (%v)", want, got)
+	}
+}
+
+func TestInputOnce(t *testing.T) {
+	d, _ := newReplyDisplay(t, "")
+
+	// The first calls may race, i.e. a query and SetEscTimeout.
+	readers := make(chan *input.Reader)
+	for range 4 {
+		go func() {
+			readers <- d.input()
+		}()
+	}
+	first := <-readers
+	for range 3 {
+		if r := <-readers; r != first {
+			t.Error("err: want the same reader for everybody")
+		}
+	}
+}
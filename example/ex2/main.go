@@ -3,9 +3,11 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/mec-nyan/termy"
+	"github.com/mec-nyan/termy/width"
 )
 
 func main() {
@@ -70,7 +72,12 @@ func showLove(screen *termy.Display) {
 	// ... or the methods.
 	screen.CurToCol(1)
 	screen.MoveDown(4)
-	screen.Print("This is some text for u 🩷")
+	// The emoji takes two columns, so the bars line up.
+	for _, msg := range []string{"This is some text for u 🩷", "This is some text for u <3"} {
+		screen.Print(msg + strings.Repeat(" ", 30-width.String(msg)) + "|")
+		screen.CurToCol(1)
+		screen.MoveDown(1)
+	}
 
 	getc()
 }
@@ -124,9 +131,12 @@ Loop:
 }
 
 func showCurPos(screen *termy.Display) {
-	x, y := screen.CurPos()
-
-	screen.Print(fmt.Sprintf("\n\nCur pos? x: %d, y: %d", x, y))
+	x, y, err := screen.CurPos()
+	if err != nil {
+		screen.Print(fmt.Sprintf("\n\nCur pos? %v", err))
+	} else {
+		screen.Print(fmt.Sprintf("\n\nCur pos? x: %d, y: %d", x, y))
+	}
 
 	getc()
 }
@@ -0,0 +1,19 @@
+package termy
+
+// EnableFocusReporting makes the terminal tell when it gets or loses the
+// focus, as input.FocusGained and input.FocusLost events.
+// It's disabled on Restore.
+func (d *Display) EnableFocusReporting() {
+	d.setMode(focusReporting, decset(1004), decrst(1004))
+}
+
+// DisableFocusReporting stops the focus events.
+func (d *Display) DisableFocusReporting() {
+	d.resetMode(focusReporting, decrst(1004))
+}
+
+// FocusReporting reports whether we enabled focus reporting.
+// NOTE: It will NOT check your emulator state directly.
+func (d *Display) FocusReporting() bool {
+	return d.is(focusReporting)
+}
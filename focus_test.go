@@ -0,0 +1,49 @@
+package termy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mec-nyan/termy/input"
+)
+
+func TestFocusReporting(t *testing.T) {
+	var out bytes.Buffer
+	d, err := NewDisplayWith(
+		WithInput(strings.NewReader("\x1b[O\x1b[I")),
+		WithOutput(&out),
+		WithoutTermios(),
+		WithEscapes(true),
+	)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	d.EnableFocusReporting()
+	if !d.FocusReporting() {
+		t.Error("err: want focus reporting enabled")
+	}
+
+	for _, want := range []input.Event{input.FocusLost{}, input.FocusGained{}} {
+		got, err := d.ReadEvent()
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if got != want {
+			t.Errorf("err: want (%T), got (%v)", want, got)
+		}
+	}
+
+	if err := d.Restore(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if d.FocusReporting() {
+		t.Error("err: want focus reporting disabled after Restore")
+	}
+
+	want := "\x1b[?1004h" + "\x1b[?1004l"
+	if got := out.String(); got != want {
+		t.Errorf("err: want (%q), got (%q)", want, got)
+	}
+}
@@ -0,0 +1,241 @@
+// Package grapheme splits text into user-perceived characters: grapheme
+// clusters, as defined by Unicode (UAX #29, extended grapheme clusters).
+//
+// A cluster can be made of several runes: "é" written as "e" plus a combining
+// accent, a flag (two regional indicators), an emoji with a skin tone, a
+// family emoji (several emoji joined with zero width joiners), a Hangul
+// syllable made of jamo, or an Indic conjunct. Moving the cursor or filling
+// cells rune by rune breaks them apart.
+//
+// It follows the rules of Unicode 17.0, the version of the unicode package's
+// tables it takes most properties from.
+package grapheme
+
+import (
+	"iter"
+	"slices"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mec-nyan/termy/byteme"
+)
+
+// property is the Grapheme_Cluster_Break property of a rune.
+type property int
+
+const (
+	other property = iota
+	cr
+	lf
+	controlChar
+	extendChar
+	zwj
+	regional
+	prependChar
+	spacingMark
+	hangulL
+	hangulV
+	hangulT
+	hangulLV
+	hangulLVT
+	pictograph
+)
+
+// Next returns the size in bytes of the first cluster in b.
+// Invalid UTF-8 is taken one byte at a time.
+func Next(b []byte) int {
+	if len(b) == 0 {
+		return 0
+	}
+	r, size := utf8.DecodeRune(b)
+
+	var s state
+	prev := s.take(r)
+	for size < len(b) {
+		r, n := utf8.DecodeRune(b[size:])
+		p := propertyOf(r)
+		if s.breaks(prev, p, r) {
+			break
+		}
+		prev = s.take(r)
+		size += n
+	}
+	return size
+}
+
+// NextString returns the size in bytes of the first cluster in str.
+func NextString(str string) int {
+	return Next(byteme.UnsafeStrToBytes(str))
+}
+
+// Count returns how many clusters there are in str.
+func Count(str string) int {
+	n := 0
+	for str != "" {
+		str = str[NextString(str):]
+		n++
+	}
+	return n
+}
+
+// Clusters iterates over the clusters in str, i.e.
+//
+//	for c := range grapheme.Clusters("🇪🇸 é") {
+//		// "🇪🇸", " " and "é".
+//	}
+func Clusters(str string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for str != "" {
+			n := NextString(str)
+			if !yield(str[:n]) {
+				return
+			}
+			str = str[n:]
+		}
+	}
+}
+
+// -------- Internal -------- //
+
+// state is what the rules need to know about the runes before the current
+// one, besides its property.
+type state struct {
+	// regionals is how many regional indicators there are in a row.
+	regionals int
+	// pict is set after an Extended_Pictographic and any Extend, and
+	// pictZWJ when a ZWJ follows them.
+	pict, pictZWJ bool
+	// conjunct is 1 after an Indic consonant (and any extend or linker), and
+	// 2 once a linker follows it.
+	conjunct int
+}
+
+// take updates the state with r, and returns its property.
+func (s *state) take(r rune) property {
+	p := propertyOf(r)
+
+	if p == regional {
+		s.regionals++
+	} else {
+		s.regionals = 0
+	}
+
+	s.pictZWJ = s.pict && p == zwj
+	s.pict = p == pictograph || s.pict && p == extendChar
+
+	switch {
+	case in(consonants, r):
+		s.conjunct = 1
+	case s.conjunct > 0 && in(linkers, r):
+		s.conjunct = 2
+	case s.conjunct > 0 && (p == extendChar || p == zwj):
+	default:
+		s.conjunct = 0
+	}
+
+	return p
+}
+
+// breaks reports whether there's a boundary between the previous rune, with
+// property "prev", and r, with property p.
+func (s *state) breaks(prev, p property, r rune) bool {
+	switch {
+	case prev == cr && p == lf: // GB3
+		return false
+	case prev == cr || prev == lf || prev == controlChar: // GB4
+		return true
+	case p == cr || p == lf || p == controlChar: // GB5
+		return true
+	case prev == hangulL && (p == hangulL || p == hangulV || p == hangulLV || p == hangulLVT): // GB6
+		return false
+	case (prev == hangulLV || prev == hangulV) && (p == hangulV || p == hangulT): // GB7
+		return false
+	case (prev == hangulLVT || prev == hangulT) && p == hangulT: // GB8
+		return false
+	case p == extendChar || p == zwj: // GB9
+		return false
+	case p == spacingMark: // GB9a
+		return false
+	case prev == prependChar: // GB9b
+		return false
+	case s.conjunct == 2 && in(consonants, r): // GB9c
+		return false
+	case s.pictZWJ && p == pictograph: // GB11
+		return false
+	case prev == regional && p == regional && s.regionals%2 == 1: // GB12, GB13
+		return false
+	}
+	return true // GB999
+}
+
+// propertyOf returns the Grapheme_Cluster_Break property of r.
+func propertyOf(r rune) property {
+	switch {
+	case r == '\r':
+		return cr
+	case r == '\n':
+		return lf
+	case r < 0x20 || r >= 0x7F && r < 0xA0:
+		return controlChar
+	case r < 0x7F:
+		return other
+	case r == 0x200D:
+		return zwj
+	case r == 0x200C || r >= 0x1F3FB && r <= 0x1F3FF || r >= 0xE0020 && r <= 0xE007F:
+		// The zero width non-joiner, emoji modifiers and tags.
+		return extendChar
+	case r >= 0x1F1E6 && r <= 0x1F1FF:
+		return regional
+	}
+
+	if p, ok := hangul(r); ok {
+		return p
+	}
+
+	switch {
+	case unicode.Is(unicode.Prepended_Concatenation_Mark, r) || in(prepend, r):
+		return prependChar
+	case unicode.In(r, extend...):
+		return extendChar
+	case unicode.In(r, control...):
+		return controlChar
+	case unicode.Is(unicode.Mc, r) && !in(notSpacing, r) || r == 0x0E33 || r == 0x0EB3:
+		return spacingMark
+	case in(pictographic, r):
+		return pictograph
+	}
+	return other
+}
+
+// hangul returns the property of r if it's a Hangul jamo or syllable.
+func hangul(r rune) (property, bool) {
+	switch {
+	case r >= 0x1100 && r <= 0x115F || r >= 0xA960 && r <= 0xA97C:
+		return hangulL, true
+	case r >= 0x1160 && r <= 0x11A7 || r >= 0xD7B0 && r <= 0xD7C6:
+		return hangulV, true
+	case r >= 0x11A8 && r <= 0x11FF || r >= 0xD7CB && r <= 0xD7FB:
+		return hangulT, true
+	case r >= 0xAC00 && r <= 0xD7A3:
+		// Every 28th syllable has no final consonant.
+		if (r-0xAC00)%28 == 0 {
+			return hangulLV, true
+		}
+		return hangulLVT, true
+	}
+	return other, false
+}
+
+// in reports whether r is in one of the sorted intervals.
+func in(table []interval, r rune) bool {
+	_, found := slices.BinarySearchFunc(table, r, func(i interval, r rune) int {
+		switch {
+		case r < i.first:
+			return 1
+		case r > i.last:
+			return -1
+		}
+		return 0
+	})
+	return found
+}
@@ -0,0 +1,92 @@
+package grapheme
+
+import (
+	"bufio"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestBreakConformance(t *testing.T) {
+	f, err := os.Open("testdata/GraphemeBreakTest.txt")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if line == 1 {
+			// The runes' properties come from the unicode package, so the
+			// data must be for the same version.
+			want := "# GraphemeBreakTest-" + unicode.Version + ".txt"
+			if got := scanner.Text(); got != want {
+				t.Fatalf("err: want (%s), got (%s)", want, got)
+			}
+		}
+		test, _, _ := strings.Cut(scanner.Text(), "#")
+		if strings.TrimSpace(test) == "" {
+			continue
+		}
+
+		// Given
+		var (
+			text    strings.Builder
+			want    []string
+			cluster strings.Builder
+		)
+		for _, field := range strings.Fields(test) {
+			switch field {
+			case "÷":
+				if cluster.Len() > 0 {
+					want = append(want, cluster.String())
+					cluster.Reset()
+				}
+			case "×":
+			default:
+				r, err := strconv.ParseUint(field, 16, 32)
+				if err != nil {
+					t.Fatalf("err: line %d: %v", line, err)
+				}
+				text.WriteRune(rune(r))
+				cluster.WriteRune(rune(r))
+			}
+		}
+
+		got := slices.Collect(Clusters(text.String()))
+		if !slices.Equal(got, want) {
+			t.Errorf("err: line %d: %s: want (%+q), got (%+q)", line, strings.TrimSpace(test), want, got)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
+
+func TestCount(t *testing.T) {
+	// Given
+	cases := []struct {
+		str  string
+		want int
+	}{
+		{str: "", want: 0},
+		{str: "hello", want: 5},
+		{str: "é", want: 1},
+		{str: "🇪🇸🇯🇵", want: 2},
+		{str: "👩‍👩‍👧!", want: 2},
+		{str: "한국어", want: 3},
+		{str: "\r\n\n", want: 2},
+		{str: "\xff\xfe", want: 2},
+	}
+
+	for _, c := range cases {
+		if got := Count(c.str); got != c.want {
+			t.Errorf("err: %q: want (%d), got (%d)", c.str, c.want, got)
+		}
+	}
+}
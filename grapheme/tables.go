@@ -0,0 +1,181 @@
+package grapheme
+
+import "unicode"
+
+// interval is a range of runes, both ends included.
+type interval struct {
+	first, last rune
+}
+
+// prepend are the Prepend characters that aren't prepended concatenation
+// marks (see unicode.Prepended_Concatenation_Mark): they go before the
+// letter they belong to.
+var prepend = []interval{
+	{0x0D4E, 0x0D4E},
+	{0x111C2, 0x111C3},
+	{0x1193F, 0x1193F},
+	{0x11941, 0x11941},
+	{0x11A3A, 0x11A3A},
+	{0x11A84, 0x11A89},
+	{0x11D46, 0x11D46},
+	{0x11F02, 0x11F02},
+}
+
+// notSpacing are the spacing marks (Mc) that don't count as SpacingMark,
+// other than the ones in unicode.Other_Grapheme_Extend (those are Extend).
+var notSpacing = []interval{
+	{0x102B, 0x102C},
+	{0x1038, 0x1038},
+	{0x1062, 0x1064},
+	{0x1067, 0x106D},
+	{0x1083, 0x1083},
+	{0x1087, 0x108C},
+	{0x108F, 0x108F},
+	{0x109A, 0x109C},
+	{0x1A61, 0x1A61},
+	{0x1A63, 0x1A64},
+	{0xAA7B, 0xAA7B},
+	{0xAA7D, 0xAA7D},
+	{0x11720, 0x11721},
+}
+
+// pictographic are the Extended_Pictographic characters, from Unicode's
+// emoji-data.txt (17.0): the emoji, and the code points set aside for more.
+var pictographic = []interval{
+	{0x00A9, 0x00A9},
+	{0x00AE, 0x00AE},
+	{0x203C, 0x203C},
+	{0x2049, 0x2049},
+	{0x2122, 0x2122},
+	{0x2139, 0x2139},
+	{0x2194, 0x2199},
+	{0x21A9, 0x21AA},
+	{0x231A, 0x231B},
+	{0x2328, 0x2328},
+	{0x2388, 0x2388},
+	{0x23CF, 0x23CF},
+	{0x23E9, 0x23F3},
+	{0x23F8, 0x23FA},
+	{0x24C2, 0x24C2},
+	{0x25AA, 0x25AB},
+	{0x25B6, 0x25B6},
+	{0x25C0, 0x25C0},
+	{0x25FB, 0x25FE},
+	{0x2600, 0x2605},
+	{0x2607, 0x2612},
+	{0x2614, 0x2685},
+	{0x2690, 0x2700},
+	{0x2702, 0x2705},
+	{0x2708, 0x2712},
+	{0x2714, 0x2714},
+	{0x2716, 0x2716},
+	{0x271D, 0x271D},
+	{0x2721, 0x2721},
+	{0x2728, 0x2728},
+	{0x2733, 0x2734},
+	{0x2744, 0x2744},
+	{0x2747, 0x2747},
+	{0x274C, 0x274C},
+	{0x274E, 0x274E},
+	{0x2753, 0x2755},
+	{0x2757, 0x2757},
+	{0x2763, 0x2767},
+	{0x2795, 0x2797},
+	{0x27A1, 0x27A1},
+	{0x27B0, 0x27B0},
+	{0x27BF, 0x27BF},
+	{0x2934, 0x2935},
+	{0x2B05, 0x2B07},
+	{0x2B1B, 0x2B1C},
+	{0x2B50, 0x2B50},
+	{0x2B55, 0x2B55},
+	{0x3030, 0x3030},
+	{0x303D, 0x303D},
+	{0x3297, 0x3297},
+	{0x3299, 0x3299},
+	{0x1F000, 0x1F0FF},
+	{0x1F10D, 0x1F10F},
+	{0x1F12F, 0x1F12F},
+	{0x1F16C, 0x1F171},
+	{0x1F17E, 0x1F17F},
+	{0x1F18E, 0x1F18E},
+	{0x1F191, 0x1F19A},
+	{0x1F1AD, 0x1F1E5},
+	{0x1F201, 0x1F20F},
+	{0x1F21A, 0x1F21A},
+	{0x1F22F, 0x1F22F},
+	{0x1F232, 0x1F23A},
+	{0x1F23C, 0x1F23F},
+	{0x1F249, 0x1F3FA},
+	{0x1F400, 0x1F53D},
+	{0x1F546, 0x1F64F},
+	{0x1F680, 0x1F6FF},
+	{0x1F774, 0x1F77F},
+	{0x1F7D5, 0x1F7FF},
+	{0x1F80C, 0x1F80F},
+	{0x1F848, 0x1F84F},
+	{0x1F85A, 0x1F85F},
+	{0x1F888, 0x1F88F},
+	{0x1F8AE, 0x1F8FF},
+	{0x1F90C, 0x1F93A},
+	{0x1F93C, 0x1F945},
+	{0x1F947, 0x1FAFF},
+	{0x1FC00, 0x1FFFD},
+}
+
+// consonants are the letters that make conjuncts (Indic_Conjunct_Break=Consonant):
+// the consonants of Devanagari, Bengali, Gujarati, Oriya, Telugu and Malayalam,
+// and the letters of Myanmar, Khmer and Balinese that take a subjoined form.
+var consonants = []interval{
+	{0x0915, 0x0939},
+	{0x0958, 0x095F},
+	{0x0978, 0x097F},
+	{0x0995, 0x09A8},
+	{0x09AA, 0x09B0},
+	{0x09B2, 0x09B2},
+	{0x09B6, 0x09B9},
+	{0x09DC, 0x09DD},
+	{0x09DF, 0x09DF},
+	{0x09F0, 0x09F1},
+	{0x0A95, 0x0AA8},
+	{0x0AAA, 0x0AB0},
+	{0x0AB2, 0x0AB3},
+	{0x0AB5, 0x0AB9},
+	{0x0AF9, 0x0AF9},
+	{0x0B15, 0x0B28},
+	{0x0B2A, 0x0B30},
+	{0x0B32, 0x0B33},
+	{0x0B35, 0x0B39},
+	{0x0B5C, 0x0B5D},
+	{0x0B5F, 0x0B5F},
+	{0x0B71, 0x0B71},
+	{0x0C15, 0x0C28},
+	{0x0C2A, 0x0C39},
+	{0x0C58, 0x0C5A},
+	{0x0D15, 0x0D3A},
+	{0x1000, 0x102A},
+	{0x1780, 0x17B3},
+	{0x1B0B, 0x1B0E},
+	{0x1B13, 0x1B33},
+	{0x1B45, 0x1B4C},
+}
+
+// linkers are the viramas that join two consonants in a conjunct
+// (Indic_Conjunct_Break=Linker), in the same scripts.
+var linkers = []interval{
+	{0x094D, 0x094D},
+	{0x09CD, 0x09CD},
+	{0x0ACD, 0x0ACD},
+	{0x0B4D, 0x0B4D},
+	{0x0C4D, 0x0C4D},
+	{0x0D4D, 0x0D4D},
+	{0x1039, 0x1039},
+	{0x17D2, 0x17D2},
+	{0x1B44, 0x1B44},
+}
+
+// control are the categories of Control characters.
+var control = []*unicode.RangeTable{unicode.Cc, unicode.Zl, unicode.Zp, unicode.Cf}
+
+// extend are the categories of Extend characters (Grapheme_Extend).
+var extend = []*unicode.RangeTable{unicode.Mn, unicode.Me, unicode.Other_Grapheme_Extend}
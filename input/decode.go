@@ -0,0 +1,420 @@
+package input
+
+import (
+	"unicode/utf8"
+)
+
+const esc = 0x1b
+
+// decode decodes the first event in b, and tells how many bytes it took.
+// If b starts with an incomplete sequence, n is 0 so you can wait for the rest,
+// unless final is set: then we make the best of what we have (i.e. a lone
+// ESC is the Escape key).
+func decode(b []byte, final bool) (ev Event, n int) {
+	if len(b) == 0 {
+		return nil, 0
+	}
+	if b[0] == esc {
+		return decodeEsc(b, final)
+	}
+	return decodeChar(b, final)
+}
+
+// decodeChar decodes a single character: either text or a control character.
+func decodeChar(b []byte, final bool) (Event, int) {
+	c := b[0]
+	if c < 0x20 || c == 0x7f {
+		return controlKey(c), 1
+	}
+	if c < utf8.RuneSelf {
+		return Key{Code: KeyRune, Rune: rune(c)}, 1
+	}
+	// Multi byte characters may be split across reads.
+	if !utf8.FullRune(b) && !final {
+		return nil, 0
+	}
+	r, size := utf8.DecodeRune(b)
+	return Key{Code: KeyRune, Rune: r}, size
+}
+
+// controlKey maps the C0 control characters (and DEL) to keys.
+func controlKey(c byte) Key {
+	switch c {
+	// Enter sends '\r', which becomes '\n' if the terminal translates CRs.
+	case '\r', '\n':
+		return Key{Code: KeyEnter}
+	case '\t':
+		return Key{Code: KeyTab}
+	// Depending on the terminal, Backspace sends DEL or BS (^H).
+	// Some terminals send the other one for Ctrl+Backspace, but we can't tell.
+	case 0x7f, 0x08:
+		return Key{Code: KeyBackspace}
+	case esc:
+		return Key{Code: KeyEscape}
+	case 0x00:
+		return Key{Code: KeyRune, Rune: ' ', Mods: ModCtrl}
+	}
+	if c <= 0x1a {
+		// Ctrl-A to Ctrl-Z.
+		return Key{Code: KeyRune, Rune: rune('a' + c - 1), Mods: ModCtrl}
+	}
+	// Ctrl-\, Ctrl-], Ctrl-^ and Ctrl-_.
+	return Key{Code: KeyRune, Rune: rune(c + 0x40), Mods: ModCtrl}
+}
+
+// decodeEsc decodes anything starting with ESC: CSI and SS3 sequences, or Alt+key.
+func decodeEsc(b []byte, final bool) (Event, int) {
+	if len(b) == 1 {
+		if final {
+			return Key{Code: KeyEscape}, 1
+		}
+		return nil, 0
+	}
+
+	var ev Event
+	var n int
+	switch b[1] {
+	case '[':
+		ev, n = decodeCSI(b, final)
+	case 'O':
+		ev, n = decodeSS3(b, final)
+	case 'P', ']', '_', '^', 'X':
+		// Control strings look like Alt+key until we see what follows.
+		if len(b) == 2 && !final {
+			return nil, 0
+		}
+		if len(b) > 2 && b[2] >= 0x30 && b[2] <= 0x3f {
+			ev, n = decodeString(b)
+			if n == 0 && !final {
+				return nil, 0
+			}
+		}
+	}
+	if n > 0 {
+		return ev, n
+	}
+	if !final && (b[1] == '[' || b[1] == 'O') {
+		return nil, 0
+	}
+
+	// ESC followed by a key means Alt was held down.
+	if b[1] == esc {
+		ev, n = decodeEsc(b[1:], final)
+	} else {
+		ev, n = decodeChar(b[1:], final)
+	}
+	if n == 0 {
+		return nil, 0
+	}
+	key, ok := ev.(Key)
+	if !ok {
+		// Whatever that is, the first ESC was on its own.
+		return Key{Code: KeyEscape}, 1
+	}
+	key.Mods |= ModAlt
+	return key, n + 1
+}
+
+// decodeString decodes the control strings terminals use for some replies:
+// DCS (ESC P), OSC (ESC ]), APC (ESC _), PM (ESC ^) and SOS (ESC X).
+// They end with ST (ESC \), or BEL.
+// It's only a string if a parameter or a private marker follows (i.e. ESC P > |
+// or ESC ] 11 ;), otherwise it's Alt+key (see decodeEsc).
+func decodeString(b []byte) (Event, int) {
+	for i := 2; i < len(b); i++ {
+		switch b[i] {
+		case 0x07:
+			return Unknown{Seq: append([]byte{}, b[:i+1]...)}, i + 1
+		case esc:
+			if i+1 == len(b) {
+				return nil, 0
+			}
+			if b[i+1] == '\\' {
+				return Unknown{Seq: append([]byte{}, b[:i+2]...)}, i + 2
+			}
+			// Not a valid string, let's skip what we've got so far.
+			return Unknown{Seq: append([]byte{}, b[:i]...)}, i
+		}
+	}
+	return nil, 0
+}
+
+// decodeSS3 decodes ESC O <final>, used by some keys in application mode.
+// Some terminals put the modifiers (see modifiers) before the final byte, i.e. ESC O 5 P.
+func decodeSS3(b []byte, final bool) (Event, int) {
+	i := 2
+	m := 0
+	for ; i < len(b) && b[i] >= '0' && b[i] <= '9'; i++ {
+		m = m*10 + int(b[i]-'0')
+	}
+	if i >= len(b) {
+		return nil, 0
+	}
+	code, ok := ss3Keys[b[i]]
+	if !ok {
+		return Unknown{Seq: append([]byte{}, b[:i+1]...)}, i + 1
+	}
+	return Key{Code: code, Mods: modifiers(m)}, i + 1
+}
+
+// modifiers decodes the xterm modifiers parameter: 1 + a bitmask with
+// Shift (1), Alt (2), Ctrl (4) and Meta (8).
+// Missing (0) or no modifiers (1) are the same.
+// With the kitty protocol on, the Reader reads them as kitty does instead
+// (see kittyLegacyKey).
+func modifiers(m int) Mod {
+	if m <= 1 {
+		return 0
+	}
+	// Higher bits (i.e. Caps Lock) are not modifiers we care about.
+	return Mod(m-1) & (ModShift | ModAlt | ModCtrl | ModMeta)
+}
+
+var ss3Keys = map[byte]KeyCode{
+	'A': KeyUp,
+	'B': KeyDown,
+	'C': KeyRight,
+	'D': KeyLeft,
+	'E': KeyBegin,
+	'F': KeyEnd,
+	'H': KeyHome,
+	'M': KeyEnter,
+	'P': KeyF1,
+	'Q': KeyF2,
+	'R': KeyF3,
+	'S': KeyF4,
+}
+
+// csi is a parsed control sequence: ESC [ <prefix> <params> <intermediates> <final>.
+type csi struct {
+	// prefix is the private marker ('<', '=', '>' or '?'), if any.
+	prefix byte
+	// params are separated by ';', each of them may have sub-parameters separated by ':'.
+	params        [][]int
+	intermediates []byte
+	final         byte
+}
+
+// param returns the i-th parameter or def, if it's missing.
+func (c csi) param(i, def int) int {
+	if i >= len(c.params) || len(c.params[i]) == 0 || c.params[i][0] < 0 {
+		return def
+	}
+	return c.params[i][0]
+}
+
+// decodeCSI decodes a control sequence.
+func decodeCSI(b []byte, final bool) (Event, int) {
+	// The Linux console uses ESC [ [ A to ESC [ [ E for F1 to F5.
+	if len(b) > 2 && b[2] == '[' {
+		if len(b) < 4 {
+			return nil, 0
+		}
+		if b[3] >= 'A' && b[3] <= 'E' {
+			return Key{Code: KeyF1 + KeyCode(b[3]-'A')}, 4
+		}
+		return Unknown{Seq: append([]byte{}, b[:4]...)}, 4
+	}
+
+	// The legacy mouse reports: ESC [ M <button> <x> <y>.
+	if len(b) > 2 && b[2] == 'M' {
+		return decodeLegacyMouse(b, final)
+	}
+
+	for i := 2; i < len(b); i++ {
+		c := b[i]
+		if c >= 0x40 && c <= 0x7e || c == '$' && rxvtShifted(b[2:i]) {
+			seq := b[:i+1]
+			return csiEvent(parseCSI(seq), seq), i + 1
+		}
+		if c < 0x20 || c > 0x7e {
+			// Not a valid sequence, let's skip what we've got so far.
+			return Unknown{Seq: append([]byte{}, b[:i]...)}, i
+		}
+	}
+	return nil, 0
+}
+
+// rxvtShifted reports whether a sequence with these params, followed by '$',
+// is a key from rxvt, which ends the shifted keys with it instead of a final
+// (i.e. ESC [ 23 $). Elsewhere '$' goes before the final, after the params.
+func rxvtShifted(params []byte) bool {
+	if len(params) == 0 {
+		return false
+	}
+	for _, c := range params {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseCSI splits a complete control sequence into its parts.
+func parseCSI(seq []byte) csi {
+	c := csi{final: seq[len(seq)-1]}
+	body := seq[2 : len(seq)-1]
+
+	if len(body) > 0 && body[0] >= '<' && body[0] <= '?' {
+		c.prefix = body[0]
+		body = body[1:]
+	}
+
+	// Intermediate bytes go after the parameters.
+	end := len(body)
+	for end > 0 && body[end-1] >= 0x20 && body[end-1] <= 0x2f {
+		end--
+	}
+	c.intermediates = body[end:]
+	body = body[:end]
+
+	if len(body) == 0 {
+		return c
+	}
+
+	param := []int{-1}
+	for _, ch := range body {
+		switch {
+		case ch >= '0' && ch <= '9':
+			last := len(param) - 1
+			if param[last] < 0 {
+				param[last] = 0
+			}
+			param[last] = param[last]*10 + int(ch-'0')
+		case ch == ':':
+			param = append(param, -1)
+		case ch == ';':
+			c.params = append(c.params, param)
+			param = []int{-1}
+		}
+	}
+	c.params = append(c.params, param)
+
+	return c
+}
+
+// csiEvent turns a control sequence into an event.
+// Keys may carry modifiers as a parameter: ESC [ 1 ; <mods> A or ESC [ 3 ; <mods> ~.
+// With the kitty protocol, the modifiers may be followed by the event type: ESC [ 1 ; <mods> : <kind> A.
+func csiEvent(c csi, seq []byte) Event {
+	if c.prefix == 0 && len(c.intermediates) == 0 {
+		mods := modifiers(c.param(1, 1))
+		kind := keyKind(c)
+		if code, ok := csiKeys[c.final]; ok {
+			return Key{Code: code, Mods: mods, Kind: kind}
+		}
+		switch c.final {
+		case '~':
+			// xterm's modifyOtherKeys: ESC [ 27 ; <mods> ; <char> ~.
+			if c.param(0, 0) == 27 && len(c.params) >= 3 {
+				return otherKey(c.param(2, 0), mods)
+			}
+			if c.param(0, 0) == 200 {
+				return pasteStart{}
+			}
+			if code, ok := tildeKeys[c.param(0, 0)]; ok {
+				return Key{Code: code, Mods: mods, Kind: kind}
+			}
+		case '$', '^':
+			if code, ok := rxvtKeys[string(seq[2:])]; ok {
+				return Key{Code: code}
+			}
+		case 'Z':
+			return Key{Code: KeyBacktab, Mods: mods, Kind: kind}
+		case 'I':
+			if len(c.params) == 0 {
+				return FocusGained{}
+			}
+		case 'O':
+			if len(c.params) == 0 {
+				return FocusLost{}
+			}
+		case 'u':
+			if key, ok := kittyKey(c); ok {
+				return key
+			}
+		}
+	}
+
+	if m, ok := sgrMouse(c); ok {
+		return m
+	}
+
+	// The answer to a query for the kitty keyboard flags: ESC [ ? <flags> u.
+	if c.prefix == '?' && c.final == 'u' && len(c.intermediates) == 0 {
+		return KeyboardFlags{Flags: KittyFlags(c.param(0, 0))}
+	}
+
+	return Unknown{Seq: append([]byte{}, seq...)}
+}
+
+// otherKey builds the key for a character reported with modifyOtherKeys.
+func otherKey(char int, mods Mod) Key {
+	switch char {
+	case '\r':
+		return Key{Code: KeyEnter, Mods: mods}
+	case '\t':
+		return Key{Code: KeyTab, Mods: mods}
+	case 0x7f, 0x08:
+		return Key{Code: KeyBackspace, Mods: mods}
+	case esc:
+		return Key{Code: KeyEscape, Mods: mods}
+	}
+	return Key{Code: KeyRune, Rune: rune(char), Mods: mods}
+}
+
+// csiKeys are the keys sent as ESC [ <final>.
+var csiKeys = map[byte]KeyCode{
+	'A': KeyUp,
+	'B': KeyDown,
+	'C': KeyRight,
+	'D': KeyLeft,
+	'E': KeyBegin,
+	'F': KeyEnd,
+	'H': KeyHome,
+	'P': KeyF1,
+	'Q': KeyF2,
+	'R': KeyF3,
+	'S': KeyF4,
+}
+
+// tildeKeys are the keys sent as ESC [ <number> ~.
+var tildeKeys = map[int]KeyCode{
+	1:  KeyHome,
+	2:  KeyInsert,
+	3:  KeyDelete,
+	4:  KeyEnd,
+	5:  KeyPgUp,
+	6:  KeyPgDn,
+	7:  KeyHome,
+	8:  KeyEnd,
+	11: KeyF1,
+	12: KeyF2,
+	13: KeyF3,
+	14: KeyF4,
+	15: KeyF5,
+	17: KeyF6,
+	18: KeyF7,
+	19: KeyF8,
+	20: KeyF9,
+	21: KeyF10,
+	23: KeyF11,
+	24: KeyF12,
+	25: KeyF13,
+	26: KeyF14,
+	28: KeyF15,
+	29: KeyF16,
+	31: KeyF17,
+	32: KeyF18,
+	33: KeyF19,
+	34: KeyF20,
+}
+
+// rxvtKeys are the function keys rxvt sends past F20, with other finals than
+// '~' (they are Shift-F11, Shift-F12, Ctrl-F1 and Ctrl-F2 for rxvt).
+var rxvtKeys = map[string]KeyCode{
+	"23$": KeyF21,
+	"24$": KeyF22,
+	"11^": KeyF23,
+	"12^": KeyF24,
+}
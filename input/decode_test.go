@@ -0,0 +1,135 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+)
+
+// decodeAll decodes every event in b, as if it was the whole input.
+func decodeAll(b []byte) []Event {
+	events := []Event{}
+	for len(b) > 0 {
+		ev, n := decode(b, true)
+		events = append(events, ev)
+		b = b[n:]
+	}
+	return events
+}
+
+func TestDecode(t *testing.T) {
+	// Given
+	cases := []struct {
+		name  string
+		input string
+		want  []Event
+	}{
+		{name: "Letter", input: "a", want: []Event{Key{Code: KeyRune, Rune: 'a'}}},
+		{name: "Text", input: "hi", want: []Event{Key{Code: KeyRune, Rune: 'h'}, Key{Code: KeyRune, Rune: 'i'}}},
+		{name: "UTF-8", input: "ñ🩷", want: []Event{Key{Code: KeyRune, Rune: 'ñ'}, Key{Code: KeyRune, Rune: '🩷'}}},
+		{name: "Enter (CR)", input: "\r", want: []Event{Key{Code: KeyEnter}}},
+		{name: "Enter (LF)", input: "\n", want: []Event{Key{Code: KeyEnter}}},
+		{name: "Tab", input: "\t", want: []Event{Key{Code: KeyTab}}},
+		{name: "Backtab", input: "\x1b[Z", want: []Event{Key{Code: KeyBacktab}}},
+		{name: "Focus gained", input: "\x1b[I", want: []Event{FocusGained{}}},
+		{name: "Focus lost", input: "\x1b[O", want: []Event{FocusLost{}}},
+		{name: "DCS", input: "\x1bP>|xterm(388)\x1b\\a", want: []Event{Unknown{Seq: []byte("\x1bP>|xterm(388)\x1b\\")}, Key{Code: KeyRune, Rune: 'a'}}},
+		{name: "OSC with BEL", input: "\x1b]11;rgb:0/0/0\a", want: []Event{Unknown{Seq: []byte("\x1b]11;rgb:0/0/0\a")}}},
+		{name: "Alt-P", input: "\x1bPa", want: []Event{Key{Code: KeyRune, Rune: 'P', Mods: ModAlt}, Key{Code: KeyRune, Rune: 'a'}}},
+		{name: "Alt-] at the end", input: "\x1b]", want: []Event{Key{Code: KeyRune, Rune: ']', Mods: ModAlt}}},
+		{name: "Unfinished string", input: "\x1b]1", want: []Event{Key{Code: KeyRune, Rune: ']', Mods: ModAlt}, Key{Code: KeyRune, Rune: '1'}}},
+		{name: "Focus between keys", input: "a\x1b[Ob", want: []Event{Key{Code: KeyRune, Rune: 'a'}, FocusLost{}, Key{Code: KeyRune, Rune: 'b'}}},
+		{name: "Backspace", input: "\x7f", want: []Event{Key{Code: KeyBackspace}}},
+		{name: "Backspace (BS)", input: "\x08", want: []Event{Key{Code: KeyBackspace}}},
+		{name: "Escape", input: "\x1b", want: []Event{Key{Code: KeyEscape}}},
+		{name: "Ctrl-A", input: "\x01", want: []Event{Key{Code: KeyRune, Rune: 'a', Mods: ModCtrl}}},
+		{name: "Ctrl-C", input: "\x03", want: []Event{Key{Code: KeyRune, Rune: 'c', Mods: ModCtrl}}},
+		{name: "Ctrl-Space", input: "\x00", want: []Event{Key{Code: KeyRune, Rune: ' ', Mods: ModCtrl}}},
+		{name: "Ctrl-]", input: "\x1d", want: []Event{Key{Code: KeyRune, Rune: ']', Mods: ModCtrl}}},
+		{name: "Alt-a", input: "\x1ba", want: []Event{Key{Code: KeyRune, Rune: 'a', Mods: ModAlt}}},
+		{name: "Alt-Ctrl-a", input: "\x1b\x01", want: []Event{Key{Code: KeyRune, Rune: 'a', Mods: ModAlt | ModCtrl}}},
+		{name: "Alt-Escape", input: "\x1b\x1b", want: []Event{Key{Code: KeyEscape, Mods: ModAlt}}},
+		{name: "Alt-Up", input: "\x1b\x1b[A", want: []Event{Key{Code: KeyUp, Mods: ModAlt}}},
+		{name: "Alt-[ (incomplete CSI)", input: "\x1b[", want: []Event{Key{Code: KeyRune, Rune: '[', Mods: ModAlt}}},
+		{name: "Up (CSI)", input: "\x1b[A", want: []Event{Key{Code: KeyUp}}},
+		{name: "Down (SS3)", input: "\x1bOB", want: []Event{Key{Code: KeyDown}}},
+		{name: "Right", input: "\x1b[C", want: []Event{Key{Code: KeyRight}}},
+		{name: "Left", input: "\x1bOD", want: []Event{Key{Code: KeyLeft}}},
+		{name: "Home (CSI)", input: "\x1b[H", want: []Event{Key{Code: KeyHome}}},
+		{name: "Home (SS3)", input: "\x1bOH", want: []Event{Key{Code: KeyHome}}},
+		{name: "Home (tilde)", input: "\x1b[1~", want: []Event{Key{Code: KeyHome}}},
+		{name: "End (SS3)", input: "\x1bOF", want: []Event{Key{Code: KeyEnd}}},
+		{name: "End (tilde)", input: "\x1b[4~", want: []Event{Key{Code: KeyEnd}}},
+		{name: "Insert", input: "\x1b[2~", want: []Event{Key{Code: KeyInsert}}},
+		{name: "Delete", input: "\x1b[3~", want: []Event{Key{Code: KeyDelete}}},
+		{name: "PgUp", input: "\x1b[5~", want: []Event{Key{Code: KeyPgUp}}},
+		{name: "PgDn", input: "\x1b[6~", want: []Event{Key{Code: KeyPgDn}}},
+		{name: "Keypad Enter", input: "\x1bOM", want: []Event{Key{Code: KeyEnter}}},
+		{name: "F1 (SS3)", input: "\x1bOP", want: []Event{Key{Code: KeyF1}}},
+		{name: "F4 (SS3)", input: "\x1bOS", want: []Event{Key{Code: KeyF4}}},
+		{name: "F1 (Linux console)", input: "\x1b[[A", want: []Event{Key{Code: KeyF1}}},
+		{name: "F5", input: "\x1b[15~", want: []Event{Key{Code: KeyF5}}},
+		{name: "F12", input: "\x1b[24~", want: []Event{Key{Code: KeyF12}}},
+		{name: "F20", input: "\x1b[34~", want: []Event{Key{Code: KeyF20}}},
+		{name: "F21 (rxvt)", input: "\x1b[23$", want: []Event{Key{Code: KeyF21}}},
+		{name: "F22 (rxvt)", input: "\x1b[24$", want: []Event{Key{Code: KeyF22}}},
+		{name: "F23 (rxvt)", input: "\x1b[11^", want: []Event{Key{Code: KeyF23}}},
+		{name: "F24 (rxvt)", input: "\x1b[12^", want: []Event{Key{Code: KeyF24}}},
+		{name: "F21 (rxvt) and a key", input: "\x1b[23$a", want: []Event{Key{Code: KeyF21}, Key{Code: KeyRune, Rune: 'a'}}},
+		{name: "F21 (xterm, Shift-F9)", input: "\x1b[20;2~", want: []Event{Key{Code: KeyF9, Mods: ModShift}}},
+		{name: "Not an rxvt key", input: "\x1b[4;1$y", want: []Event{Unknown{Seq: []byte("\x1b[4;1$y")}}},
+		// Modifiers.
+		{name: "Ctrl-Up", input: "\x1b[1;5A", want: []Event{Key{Code: KeyUp, Mods: ModCtrl}}},
+		{name: "Alt-Shift-Home", input: "\x1b[1;4H", want: []Event{Key{Code: KeyHome, Mods: ModAlt | ModShift}}},
+		{name: "Meta-Delete", input: "\x1b[3;9~", want: []Event{Key{Code: KeyDelete, Mods: ModMeta}}},
+		{name: "All modifiers", input: "\x1b[6;16~", want: []Event{Key{Code: KeyPgDn, Mods: ModShift | ModAlt | ModCtrl | ModMeta}}},
+		{name: "Ctrl-F1 (SS3)", input: "\x1bO5P", want: []Event{Key{Code: KeyF1, Mods: ModCtrl}}},
+		{name: "Shift-Backtab", input: "\x1b[1;2Z", want: []Event{Key{Code: KeyBacktab, Mods: ModShift}}},
+		{name: "Ignore locks", input: "\x1b[1;69B", want: []Event{Key{Code: KeyDown, Mods: ModCtrl}}},
+		{name: "modifyOtherKeys Ctrl-i", input: "\x1b[27;5;105~", want: []Event{Key{Code: KeyRune, Rune: 'i', Mods: ModCtrl}}},
+		{name: "modifyOtherKeys Meta-a", input: "\x1b[27;9;97~", want: []Event{Key{Code: KeyRune, Rune: 'a', Mods: ModMeta}}},
+		{name: "modifyOtherKeys Shift-Enter", input: "\x1b[27;2;13~", want: []Event{Key{Code: KeyEnter, Mods: ModShift}}},
+		{name: "Unknown CSI", input: "\x1b[99~", want: []Event{Unknown{Seq: []byte("\x1b[99~")}}},
+		{
+			name:  "Mixed",
+			input: "a\x1b[Ab",
+			want:  []Event{Key{Code: KeyRune, Rune: 'a'}, Key{Code: KeyUp}, Key{Code: KeyRune, Rune: 'b'}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := decodeAll([]byte(c.input))
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("err: want (%v), got (%v)", c.want, got)
+			}
+		})
+	}
+}
+
+func TestDecodeIncomplete(t *testing.T) {
+	for _, input := range []string{"\x1b", "\x1b[", "\x1b[1", "\x1b[15", "\x1bO", "\x1b[[", "\xf0\x9f", "\x1bP", "\x1bP>|xterm", "\x1b]11;rgb:0/0/0\x1b"} {
+		if ev, n := decode([]byte(input), false); n != 0 {
+			t.Errorf("err: %q: want to wait for more, got (%v, %d)", input, ev, n)
+		}
+	}
+}
+
+func TestKeyString(t *testing.T) {
+	// Given
+	cases := []struct {
+		key  Key
+		want string
+	}{
+		{key: Key{Code: KeyRune, Rune: 'a'}, want: "a"},
+		{key: Key{Code: KeyRune, Rune: 'a', Mods: ModCtrl | ModAlt}, want: "Ctrl+Alt+a"},
+		{key: Key{Code: KeyRune, Rune: ' ', Mods: ModCtrl}, want: "Ctrl+Space"},
+		{key: Key{Code: KeyF5, Mods: ModShift}, want: "Shift+F5"},
+		{key: Key{Code: KeyPgDn}, want: "PgDn"},
+	}
+
+	for _, c := range cases {
+		if got := c.key.String(); got != c.want {
+			t.Errorf("err: want (%s), got (%s)", c.want, got)
+		}
+	}
+}
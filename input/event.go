@@ -0,0 +1,224 @@
+// Package input decodes what the terminal sends us (keys, etc.) into events.
+package input
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Event is anything we can read from the terminal.
+// Use a type switch to find out what it is (i.e. Key).
+type Event interface {
+	event()
+}
+
+// KeyCode identifies a key.
+// Keys that produce text use KeyRune, and the text goes in Key.Rune.
+type KeyCode int
+
+const (
+	KeyRune KeyCode = iota
+	KeyEnter
+	KeyTab
+	KeyBacktab
+	KeyBackspace
+	KeyEscape
+	KeyUp
+	KeyDown
+	KeyRight
+	KeyLeft
+	KeyBegin
+	KeyHome
+	KeyEnd
+	KeyInsert
+	KeyDelete
+	KeyPgUp
+	KeyPgDn
+	KeyF1
+	KeyF2
+	KeyF3
+	KeyF4
+	KeyF5
+	KeyF6
+	KeyF7
+	KeyF8
+	KeyF9
+	KeyF10
+	KeyF11
+	KeyF12
+	KeyF13
+	KeyF14
+	KeyF15
+	KeyF16
+	KeyF17
+	KeyF18
+	KeyF19
+	KeyF20
+	KeyF21
+	KeyF22
+	KeyF23
+	KeyF24
+)
+
+var keyNames = map[KeyCode]string{
+	KeyEnter:     "Enter",
+	KeyTab:       "Tab",
+	KeyBacktab:   "Backtab",
+	KeyBackspace: "Backspace",
+	KeyEscape:    "Escape",
+	KeyUp:        "Up",
+	KeyDown:      "Down",
+	KeyRight:     "Right",
+	KeyLeft:      "Left",
+	KeyBegin:     "Begin",
+	KeyHome:      "Home",
+	KeyEnd:       "End",
+	KeyInsert:    "Insert",
+	KeyDelete:    "Delete",
+	KeyPgUp:      "PgUp",
+	KeyPgDn:      "PgDn",
+}
+
+func (k KeyCode) String() string {
+	if k >= KeyF1 && k <= KeyF24 {
+		return "F" + strconv.Itoa(int(k-KeyF1)+1)
+	}
+	if name, ok := keyNames[k]; ok {
+		return name
+	}
+	if k == KeyRune {
+		return "Rune"
+	}
+	return "KeyCode(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Mod is a bitmask with the modifiers held down while a key was pressed.
+type Mod uint8
+
+const (
+	ModShift Mod = 1 << iota
+	ModAlt
+	ModCtrl
+	ModMeta
+	// Super and Hyper are only reported by the kitty keyboard protocol.
+	ModSuper
+	ModHyper
+)
+
+func (m Mod) String() string {
+	names := []string{}
+	if m&ModCtrl != 0 {
+		names = append(names, "Ctrl")
+	}
+	if m&ModAlt != 0 {
+		names = append(names, "Alt")
+	}
+	if m&ModShift != 0 {
+		names = append(names, "Shift")
+	}
+	if m&ModMeta != 0 {
+		names = append(names, "Meta")
+	}
+	if m&ModSuper != 0 {
+		names = append(names, "Super")
+	}
+	if m&ModHyper != 0 {
+		names = append(names, "Hyper")
+	}
+	return strings.Join(names, "+")
+}
+
+// KeyKind tells whether a key was pressed, repeated or released.
+// Only the kitty keyboard protocol reports repeats and releases
+// (see KittyReportEvents), so you'll get presses otherwise.
+type KeyKind uint8
+
+const (
+	KeyPress KeyKind = iota
+	KeyRepeat
+	KeyRelease
+)
+
+func (k KeyKind) String() string {
+	switch k {
+	case KeyPress:
+		return "Press"
+	case KeyRepeat:
+		return "Repeat"
+	case KeyRelease:
+		return "Release"
+	}
+	return "KeyKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Key is a key press.
+// For keys that produce text, Code is KeyRune and Rune holds the character.
+// Control characters are reported as the corresponding letter with ModCtrl,
+// i.e. Ctrl-A is Key{Code: KeyRune, Rune: 'a', Mods: ModCtrl}.
+type Key struct {
+	Code KeyCode
+	Rune rune
+	Mods Mod
+	Kind KeyKind
+
+	// The kitty keyboard protocol can report some extra information.
+	// Shifted is the character produced with Shift and Base is the key in the
+	// standard (US) layout (see KittyReportAlternates).
+	Shifted, Base rune
+	// Text is the text produced by the key (see KittyReportText).
+	Text string
+}
+
+func (Key) event() {}
+
+// String returns a readable description of the key, i.e. "Ctrl+Alt+a" or "Shift+F5".
+func (k Key) String() string {
+	name := k.Code.String()
+	if k.Code == KeyRune {
+		name = string(k.Rune)
+		if k.Rune == ' ' {
+			name = "Space"
+		}
+	}
+	if k.Mods != 0 {
+		name = k.Mods.String() + "+" + name
+	}
+	if k.Kind != KeyPress {
+		name += " (" + k.Kind.String() + ")"
+	}
+	return name
+}
+
+// FocusGained is reported when the terminal gets the focus, with focus
+// reporting enabled (see termy's Display.EnableFocusReporting).
+type FocusGained struct{}
+
+func (FocusGained) event() {}
+
+// FocusLost is reported when the terminal loses the focus.
+type FocusLost struct{}
+
+func (FocusLost) event() {}
+
+// Resize is reported when the terminal changes size, in characters.
+// It doesn't come from the input, but from termy's Display.Events.
+type Resize struct {
+	Rows, Cols int
+}
+
+func (Resize) event() {}
+
+// Custom carries whatever you post with termy's Display.Post, i.e. a tick
+// from your own timer, so it's handled with everything else.
+type Custom struct {
+	Value any
+}
+
+func (Custom) event() {}
+
+// Unknown holds a sequence we couldn't make sense of.
+type Unknown struct {
+	Seq []byte
+}
+
+func (Unknown) event() {}
@@ -0,0 +1,74 @@
+package input
+
+import "sync"
+
+// interceptor takes the sequences a query is waiting for (see Reader.Intercept).
+type interceptor struct {
+	match func(seq []byte) bool
+	reply func(seq []byte)
+}
+
+// interceptors is the list of queries waiting for a reply.
+// It's safe to use from several goroutines: queries register themselves
+// while someone else is reading.
+type interceptors struct {
+	mu   sync.Mutex
+	list []*interceptor
+}
+
+// Intercept hands the next sequence accepted by match to reply, instead of
+// decoding it as an event. Use it to get the terminal's answers to your
+// queries (i.e. the cursor position), which can look just like keys.
+// Sequences are matched as they were read, and only the complete ones: plain
+// text is never intercepted, and neither are pastes.
+//
+// It's safe to call while another goroutine is reading: reply is called from
+// that goroutine, so it shouldn't block. Call stop when you no longer want
+// the reply (it's only handed once anyway).
+func (r *Reader) Intercept(match func(seq []byte) bool, reply func(seq []byte)) (stop func()) {
+	i := &interceptor{match: match, reply: reply}
+
+	r.queries.mu.Lock()
+	r.queries.list = append(r.queries.list, i)
+	r.queries.mu.Unlock()
+
+	return func() { r.queries.remove(i) }
+}
+
+// -------- Internal -------- //
+
+// intercept hands seq to the first interceptor that wants it, reporting whether there was one.
+func (q *interceptors) intercept(seq []byte) bool {
+	if len(seq) == 0 || seq[0] != esc {
+		return false
+	}
+
+	q.mu.Lock()
+	var found *interceptor
+	for _, i := range q.list {
+		if i.match(seq) {
+			found = i
+			break
+		}
+	}
+	q.mu.Unlock()
+
+	if found == nil {
+		return false
+	}
+	q.remove(found)
+	found.reply(append([]byte{}, seq...))
+	return true
+}
+
+func (q *interceptors) remove(i *interceptor) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for j, other := range q.list {
+		if other == i {
+			q.list = append(q.list[:j], q.list[j+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package input
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestIntercept(t *testing.T) {
+	cpr := regexp.MustCompile(`^\x1b\[\d+;\d+R$`)
+
+	// Given
+	cases := []struct {
+		name   string
+		chunks []string
+		want   []Event
+		reply  string
+	}{
+		{
+			name:   "Reply alone",
+			chunks: []string{"\x1b[5;10R"},
+			want:   []Event{},
+			reply:  "\x1b[5;10R",
+		},
+		{
+			name:   "Keys around",
+			chunks: []string{"a\x1b[A\x1b[", "12;4", "0Rb"},
+			want:   []Event{Key{Code: KeyRune, Rune: 'a'}, Key{Code: KeyUp}, Key{Code: KeyRune, Rune: 'b'}},
+			reply:  "\x1b[12;40R",
+		},
+		{
+			name:   "Only the first reply",
+			chunks: []string{"\x1b[1;2R\x1b[1;2R"},
+			want:   []Event{Key{Code: KeyF3, Mods: ModShift}},
+			reply:  "\x1b[1;2R",
+		},
+		{
+			name:   "Not in pastes",
+			chunks: []string{"\x1b[200~\x1b[1;1R\x1b[201~"},
+			want:   []Event{Paste{Text: "\x1b[1;1R"}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := NewReader(&chunkReader{chunks: c.chunks})
+			var reply []byte
+			stop := r.Intercept(cpr.Match, func(seq []byte) { reply = seq })
+			defer stop()
+
+			got := readAll(t, r)
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("err: want (%v), got (%v)", c.want, got)
+			}
+			if !bytes.Equal(reply, []byte(c.reply)) {
+				t.Errorf("err: reply: want (%q), got (%q)", c.reply, reply)
+			}
+		})
+	}
+}
+
+func TestInterceptStop(t *testing.T) {
+	r := NewReader(&chunkReader{chunks: []string{"\x1b[1;2R"}})
+	stop := r.Intercept(func([]byte) bool { return true }, func([]byte) {
+		t.Error("err: intercepted after stop")
+	})
+	stop()
+
+	want := []Event{Key{Code: KeyF3, Mods: ModShift}}
+	if got := readAll(t, r); !reflect.DeepEqual(got, want) {
+		t.Errorf("err: want (%v), got (%v)", want, got)
+	}
+}
@@ -0,0 +1,177 @@
+package input
+
+import "strings"
+
+// KittyFlags are the progressive enhancements of the kitty keyboard protocol.
+// See https://sw.kovidgoyal.net/kitty/keyboard-protocol/
+type KittyFlags int
+
+const (
+	// KittyDisambiguate reports keys that are ambiguous otherwise (i.e. Ctrl-I vs Tab, or Esc) as CSI u sequences.
+	KittyDisambiguate KittyFlags = 1 << iota
+	// KittyReportEvents reports key repeats and releases too.
+	KittyReportEvents
+	// KittyReportAlternates reports the shifted key and the key in the base layout.
+	KittyReportAlternates
+	// KittyReportAll reports every key (even Enter, Tab, etc.) as a CSI u sequence.
+	KittyReportAll
+	// KittyReportText reports the text produced by the keys.
+	KittyReportText
+)
+
+// KeyboardFlags is the terminal's answer when asked for the kitty keyboard flags in use.
+type KeyboardFlags struct {
+	Flags KittyFlags
+}
+
+func (KeyboardFlags) event() {}
+
+// kittyModifiers decodes the modifiers parameter used by the kitty protocol.
+// Unlike xterm (see modifiers), bit 8 is Super and Meta has its own bit.
+func kittyModifiers(m int) Mod {
+	if m <= 1 {
+		return 0
+	}
+	m--
+	var mods Mod
+	mods |= Mod(m) & (ModShift | ModAlt | ModCtrl)
+	if m&8 != 0 {
+		mods |= ModSuper
+	}
+	if m&16 != 0 {
+		mods |= ModHyper
+	}
+	if m&32 != 0 {
+		mods |= ModMeta
+	}
+	return mods
+}
+
+// kittyLegacyKey decodes again the modifiers of a key sent the legacy way
+// (ESC [ 1 ; <mods> A or ESC [ 3 ; <mods> ~) while the kitty protocol is on.
+func kittyLegacyKey(ev Event, seq []byte) Event {
+	key, ok := ev.(Key)
+	if !ok || len(seq) < 3 || seq[0] != esc || seq[1] != '[' || seq[2] == '[' {
+		return ev
+	}
+	c := parseCSI(seq)
+	// CSI u is kitty's already, and modifyOtherKeys is xterm's.
+	if c.final == 'u' || c.final == '~' && c.param(0, 0) == 27 {
+		return ev
+	}
+	key.Mods = kittyModifiers(c.param(1, 1))
+	return key
+}
+
+// keyKind decodes the event type sub-parameter: 1 press, 2 repeat and 3 release.
+func keyKind(c csi) KeyKind {
+	if len(c.params) < 2 || len(c.params[1]) < 2 {
+		return KeyPress
+	}
+	switch c.params[1][1] {
+	case 2:
+		return KeyRepeat
+	case 3:
+		return KeyRelease
+	}
+	return KeyPress
+}
+
+// kittyKey decodes ESC [ <code>:<shifted>:<base> ; <mods>:<kind> ; <text> u.
+func kittyKey(c csi) (Key, bool) {
+	if len(c.params) == 0 || len(c.params[0]) == 0 || c.params[0][0] < 0 {
+		return Key{}, false
+	}
+	code := c.params[0][0]
+
+	key, ok := kittyCodeKey(code)
+	if !ok {
+		return Key{}, false
+	}
+	key.Mods = kittyModifiers(c.param(1, 1))
+	key.Kind = keyKind(c)
+
+	if alternates := c.params[0]; len(alternates) > 1 {
+		if alternates[1] > 0 {
+			key.Shifted = rune(alternates[1])
+		}
+		if len(alternates) > 2 && alternates[2] > 0 {
+			key.Base = rune(alternates[2])
+		}
+	}
+
+	if len(c.params) > 2 {
+		var text strings.Builder
+		for _, r := range c.params[2] {
+			if r > 0 {
+				text.WriteRune(rune(r))
+			}
+		}
+		key.Text = text.String()
+	}
+
+	return key, true
+}
+
+// kittyCodeKey maps a kitty key code (a unicode code point or one of the
+// functional keys in the private use area) to a key.
+func kittyCodeKey(code int) (Key, bool) {
+	switch code {
+	case '\r':
+		return Key{Code: KeyEnter}, true
+	case '\t':
+		return Key{Code: KeyTab}, true
+	case 0x7f, 0x08:
+		return Key{Code: KeyBackspace}, true
+	case esc:
+		return Key{Code: KeyEscape}, true
+	}
+	if k, ok := kittyFunctional[code]; ok {
+		return k, true
+	}
+	if code >= kittyF13 && code <= kittyF13+11 {
+		return Key{Code: KeyF13 + KeyCode(code-kittyF13)}, true
+	}
+	// The private use area holds other functional keys (media keys, modifiers, etc).
+	if code >= 57344 && code <= 63743 {
+		return Key{}, false
+	}
+	return Key{Code: KeyRune, Rune: rune(code)}, true
+}
+
+// kittyF13 is the code for F13, F14 to F24 follow.
+const kittyF13 = 57376
+
+// kittyFunctional are the keys with a code of their own in the kitty protocol.
+// The keypad keys are reported as the keys they stand for.
+var kittyFunctional = map[int]Key{
+	57399: {Code: KeyRune, Rune: '0'},
+	57400: {Code: KeyRune, Rune: '1'},
+	57401: {Code: KeyRune, Rune: '2'},
+	57402: {Code: KeyRune, Rune: '3'},
+	57403: {Code: KeyRune, Rune: '4'},
+	57404: {Code: KeyRune, Rune: '5'},
+	57405: {Code: KeyRune, Rune: '6'},
+	57406: {Code: KeyRune, Rune: '7'},
+	57407: {Code: KeyRune, Rune: '8'},
+	57408: {Code: KeyRune, Rune: '9'},
+	57409: {Code: KeyRune, Rune: '.'},
+	57410: {Code: KeyRune, Rune: '/'},
+	57411: {Code: KeyRune, Rune: '*'},
+	57412: {Code: KeyRune, Rune: '-'},
+	57413: {Code: KeyRune, Rune: '+'},
+	57414: {Code: KeyEnter},
+	57415: {Code: KeyRune, Rune: '='},
+	57416: {Code: KeyRune, Rune: ','},
+	57417: {Code: KeyLeft},
+	57418: {Code: KeyRight},
+	57419: {Code: KeyUp},
+	57420: {Code: KeyDown},
+	57421: {Code: KeyPgUp},
+	57422: {Code: KeyPgDn},
+	57423: {Code: KeyHome},
+	57424: {Code: KeyEnd},
+	57425: {Code: KeyInsert},
+	57426: {Code: KeyDelete},
+	57427: {Code: KeyBegin},
+}
@@ -0,0 +1,100 @@
+package input
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeKitty(t *testing.T) {
+	// Given
+	cases := []struct {
+		name  string
+		input string
+		want  Event
+	}{
+		{name: "a", input: "\x1b[97u", want: Key{Code: KeyRune, Rune: 'a'}},
+		{name: "Ctrl-i (not Tab)", input: "\x1b[105;5u", want: Key{Code: KeyRune, Rune: 'i', Mods: ModCtrl}},
+		{name: "Tab", input: "\x1b[9u", want: Key{Code: KeyTab}},
+		{name: "Escape", input: "\x1b[27u", want: Key{Code: KeyEscape}},
+		{name: "Enter", input: "\x1b[13u", want: Key{Code: KeyEnter}},
+		{name: "Backspace", input: "\x1b[127u", want: Key{Code: KeyBackspace}},
+		{name: "Press (explicit)", input: "\x1b[97;1:1u", want: Key{Code: KeyRune, Rune: 'a'}},
+		{name: "Repeat", input: "\x1b[97;1:2u", want: Key{Code: KeyRune, Rune: 'a', Kind: KeyRepeat}},
+		{name: "Release", input: "\x1b[97;1:3u", want: Key{Code: KeyRune, Rune: 'a', Kind: KeyRelease}},
+		{name: "Ctrl-Shift release", input: "\x1b[97;6:3u", want: Key{Code: KeyRune, Rune: 'a', Mods: ModCtrl | ModShift, Kind: KeyRelease}},
+		{name: "Super", input: "\x1b[97;9u", want: Key{Code: KeyRune, Rune: 'a', Mods: ModSuper}},
+		{name: "Hyper", input: "\x1b[97;17u", want: Key{Code: KeyRune, Rune: 'a', Mods: ModHyper}},
+		{name: "Meta", input: "\x1b[97;33u", want: Key{Code: KeyRune, Rune: 'a', Mods: ModMeta}},
+		{name: "Caps Lock is ignored", input: "\x1b[97;65u", want: Key{Code: KeyRune, Rune: 'a'}},
+		{
+			name:  "Alternates",
+			input: "\x1b[97:65;2u",
+			want:  Key{Code: KeyRune, Rune: 'a', Mods: ModShift, Shifted: 'A'},
+		},
+		{
+			name:  "Base layout",
+			input: "\x1b[1092::97;5u",
+			want:  Key{Code: KeyRune, Rune: 'ф', Mods: ModCtrl, Base: 'a'},
+		},
+		{
+			name:  "Text",
+			input: "\x1b[97:65;2;65u",
+			want:  Key{Code: KeyRune, Rune: 'a', Mods: ModShift, Shifted: 'A', Text: "A"},
+		},
+		{name: "F13", input: "\x1b[57376u", want: Key{Code: KeyF13}},
+		{name: "F21", input: "\x1b[57384u", want: Key{Code: KeyF21}},
+		{name: "F24", input: "\x1b[57387u", want: Key{Code: KeyF24}},
+		{name: "Keypad 5", input: "\x1b[57404u", want: Key{Code: KeyRune, Rune: '5'}},
+		{name: "Keypad Enter", input: "\x1b[57414u", want: Key{Code: KeyEnter}},
+		{name: "Up release (legacy form)", input: "\x1b[1;1:3A", want: Key{Code: KeyUp, Kind: KeyRelease}},
+		{name: "Delete repeat (legacy form)", input: "\x1b[3;5:2~", want: Key{Code: KeyDelete, Mods: ModCtrl, Kind: KeyRepeat}},
+		{name: "Media key", input: "\x1b[57428u", want: Unknown{Seq: []byte("\x1b[57428u")}},
+		{name: "Flags reply", input: "\x1b[?11u", want: KeyboardFlags{Flags: KittyDisambiguate | KittyReportEvents | KittyReportAll}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := decodeAll([]byte(c.input))
+			if len(got) != 1 || !reflect.DeepEqual(got[0], c.want) {
+				t.Errorf("err: want (%v), got (%v)", c.want, got)
+			}
+		})
+	}
+}
+
+func TestKittyLegacyKeys(t *testing.T) {
+	// Given
+	cases := []struct {
+		name  string
+		kitty bool
+		input string
+		want  Event
+	}{
+		{name: "Meta-Up (xterm)", input: "\x1b[1;9A", want: Key{Code: KeyUp, Mods: ModMeta}},
+		{name: "Meta-Delete (xterm)", input: "\x1b[3;9~", want: Key{Code: KeyDelete, Mods: ModMeta}},
+		{name: "Super-Up", kitty: true, input: "\x1b[1;9A", want: Key{Code: KeyUp, Mods: ModSuper}},
+		{name: "Super-Delete", kitty: true, input: "\x1b[3;9~", want: Key{Code: KeyDelete, Mods: ModSuper}},
+		{name: "Hyper-Right", kitty: true, input: "\x1b[1;17C", want: Key{Code: KeyRight, Mods: ModHyper}},
+		{name: "Meta-F5", kitty: true, input: "\x1b[15;33~", want: Key{Code: KeyF5, Mods: ModMeta}},
+		{name: "Num Lock is ignored", kitty: true, input: "\x1b[3;130~", want: Key{Code: KeyDelete, Mods: ModShift}},
+		{name: "Release", kitty: true, input: "\x1b[1;9:3A", want: Key{Code: KeyUp, Mods: ModSuper, Kind: KeyRelease}},
+		{name: "CSI u", kitty: true, input: "\x1b[97;9u", want: Key{Code: KeyRune, Rune: 'a', Mods: ModSuper}},
+		{name: "SS3", kitty: true, input: "\x1bO9P", want: Key{Code: KeyF1, Mods: ModMeta}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := NewReader(strings.NewReader(c.input))
+			r.SetKittyKeyboard(c.kitty)
+
+			got, err := r.ReadEvent()
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("err: want (%v), got (%v)", c.want, got)
+			}
+		})
+	}
+}
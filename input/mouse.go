@@ -0,0 +1,153 @@
+package input
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// MouseButton is the button behind a mouse event.
+type MouseButton int
+
+const (
+	// MouseNone is used for motion with no button pressed, and for releases
+	// with the legacy encoding, which doesn't tell which button was released.
+	MouseNone MouseButton = iota
+	MouseLeft
+	MouseMiddle
+	MouseRight
+	MouseWheelUp
+	MouseWheelDown
+	MouseWheelLeft
+	MouseWheelRight
+	MouseBackward
+	MouseForward
+	MouseButton10
+	MouseButton11
+)
+
+var mouseButtonNames = []string{
+	"None", "Left", "Middle", "Right",
+	"WheelUp", "WheelDown", "WheelLeft", "WheelRight",
+	"Backward", "Forward", "Button10", "Button11",
+}
+
+func (b MouseButton) String() string {
+	if b >= 0 && int(b) < len(mouseButtonNames) {
+		return mouseButtonNames[b]
+	}
+	return "MouseButton(" + strconv.Itoa(int(b)) + ")"
+}
+
+// Wheel reports whether the "button" is actually the wheel.
+func (b MouseButton) Wheel() bool {
+	return b >= MouseWheelUp && b <= MouseWheelRight
+}
+
+// MouseAction tells what happened: a press, a release or some motion.
+// The wheel only reports presses.
+type MouseAction int
+
+const (
+	MousePress MouseAction = iota
+	MouseRelease
+	MouseMotion
+)
+
+func (a MouseAction) String() string {
+	switch a {
+	case MousePress:
+		return "Press"
+	case MouseRelease:
+		return "Release"
+	case MouseMotion:
+		return "Motion"
+	}
+	return "MouseAction(" + strconv.Itoa(int(a)) + ")"
+}
+
+// Mouse is a mouse report (see termy's Display.EnableMouse).
+// X and Y start at 1 from the top left corner, like Display.MoveTo. With the
+// SGR-pixel encoding they are pixels instead of cells.
+// The terminal only reports Shift, Alt and Ctrl, and it usually keeps some
+// combinations (i.e. Shift+click) to itself.
+type Mouse struct {
+	Button MouseButton
+	Action MouseAction
+	X, Y   int
+	Mods   Mod
+}
+
+func (Mouse) event() {}
+
+func (m Mouse) String() string {
+	s := m.Button.String() + " " + m.Action.String()
+	if m.Mods != 0 {
+		s = m.Mods.String() + "+" + s
+	}
+	return fmt.Sprintf("%s at %d,%d", s, m.X, m.Y)
+}
+
+// -------- Internal -------- //
+
+// mouseEvent decodes the button byte of a report: the two low bits are the
+// button, then come the modifiers, motion and the wheel and extra buttons.
+func mouseEvent(b, x, y int, release bool) Mouse {
+	m := Mouse{X: x, Y: y}
+
+	if b&4 != 0 {
+		m.Mods |= ModShift
+	}
+	if b&8 != 0 {
+		m.Mods |= ModAlt
+	}
+	if b&16 != 0 {
+		m.Mods |= ModCtrl
+	}
+
+	low := MouseButton(b & 3)
+	switch {
+	case b&128 != 0:
+		m.Button = MouseBackward + low
+	case b&64 != 0:
+		m.Button = MouseWheelUp + low
+	case low == 3:
+		// No button: a legacy release, or motion.
+		m.Button = MouseNone
+		release = b&32 == 0
+	default:
+		m.Button = MouseLeft + low
+	}
+
+	switch {
+	case b&32 != 0:
+		m.Action = MouseMotion
+	case release:
+		m.Action = MouseRelease
+	}
+
+	return m
+}
+
+// sgrMouse decodes ESC [ < <button> ; <x> ; <y> M for presses (and motion),
+// or m for releases.
+func sgrMouse(c csi) (Mouse, bool) {
+	if c.prefix != '<' || (c.final != 'M' && c.final != 'm') || len(c.params) != 3 {
+		return Mouse{}, false
+	}
+	return mouseEvent(c.param(0, 0), c.param(1, 1), c.param(2, 1), c.final == 'm'), true
+}
+
+// decodeLegacyMouse decodes ESC [ M <button> <x> <y>, where each of the
+// three is a byte with 32 added.
+func decodeLegacyMouse(b []byte, final bool) (Event, int) {
+	if len(b) < 6 {
+		if final {
+			return Unknown{Seq: append([]byte{}, b...)}, len(b)
+		}
+		return nil, 0
+	}
+	if b[3] < 32 || b[4] <= 32 || b[5] <= 32 {
+		return Unknown{Seq: append([]byte{}, b[:3]...)}, 3
+	}
+	return mouseEvent(int(b[3]-32), int(b[4]-32), int(b[5]-32), false), 6
+}
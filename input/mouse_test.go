@@ -0,0 +1,64 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeMouse(t *testing.T) {
+	// Given
+	cases := []struct {
+		name  string
+		input string
+		want  Event
+	}{
+		// SGR (1006).
+		{name: "SGR left press", input: "\x1b[<0;10;5M", want: Mouse{Button: MouseLeft, X: 10, Y: 5}},
+		{name: "SGR left release", input: "\x1b[<0;10;5m", want: Mouse{Button: MouseLeft, Action: MouseRelease, X: 10, Y: 5}},
+		{name: "SGR right press", input: "\x1b[<2;1;1M", want: Mouse{Button: MouseRight, X: 1, Y: 1}},
+		{name: "SGR middle release", input: "\x1b[<1;3;4m", want: Mouse{Button: MouseMiddle, Action: MouseRelease, X: 3, Y: 4}},
+		{name: "SGR drag", input: "\x1b[<32;11;5M", want: Mouse{Button: MouseLeft, Action: MouseMotion, X: 11, Y: 5}},
+		{name: "SGR motion", input: "\x1b[<35;300;200M", want: Mouse{Button: MouseNone, Action: MouseMotion, X: 300, Y: 200}},
+		{name: "SGR wheel up", input: "\x1b[<64;7;8M", want: Mouse{Button: MouseWheelUp, X: 7, Y: 8}},
+		{name: "SGR wheel down", input: "\x1b[<65;7;8M", want: Mouse{Button: MouseWheelDown, X: 7, Y: 8}},
+		{name: "SGR wheel right", input: "\x1b[<67;7;8M", want: Mouse{Button: MouseWheelRight, X: 7, Y: 8}},
+		{name: "SGR backward", input: "\x1b[<128;2;2M", want: Mouse{Button: MouseBackward, X: 2, Y: 2}},
+		{name: "SGR forward", input: "\x1b[<129;2;2m", want: Mouse{Button: MouseForward, Action: MouseRelease, X: 2, Y: 2}},
+		{name: "SGR shift", input: "\x1b[<4;1;1M", want: Mouse{Button: MouseLeft, X: 1, Y: 1, Mods: ModShift}},
+		{name: "SGR alt", input: "\x1b[<8;1;1M", want: Mouse{Button: MouseLeft, X: 1, Y: 1, Mods: ModAlt}},
+		{name: "SGR ctrl wheel", input: "\x1b[<80;1;1M", want: Mouse{Button: MouseWheelUp, X: 1, Y: 1, Mods: ModCtrl}},
+		{name: "SGR ctrl-alt-shift drag", input: "\x1b[<62;4;4M", want: Mouse{Button: MouseRight, Action: MouseMotion, X: 4, Y: 4, Mods: ModCtrl | ModAlt | ModShift}},
+		{name: "SGR missing coordinate", input: "\x1b[<0;10M", want: Unknown{Seq: []byte("\x1b[<0;10M")}},
+		// Legacy (X10 and normal tracking without an extended encoding).
+		{name: "Legacy left press", input: "\x1b[M *%", want: Mouse{Button: MouseLeft, X: 10, Y: 5}},
+		{name: "Legacy release", input: "\x1b[M#*%", want: Mouse{Button: MouseNone, Action: MouseRelease, X: 10, Y: 5}},
+		{name: "Legacy wheel down", input: "\x1b[Ma!!", want: Mouse{Button: MouseWheelDown, X: 1, Y: 1}},
+		{name: "Legacy motion", input: "\x1b[MC!!", want: Mouse{Button: MouseNone, Action: MouseMotion, X: 1, Y: 1}},
+		{name: "Legacy ctrl right", input: "\x1b[M2!!", want: Mouse{Button: MouseRight, X: 1, Y: 1, Mods: ModCtrl}},
+		{name: "Legacy far away", input: "\x1b[M \xff\xff", want: Mouse{Button: MouseLeft, X: 223, Y: 223}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := decodeAll([]byte(c.input))
+			if len(got) != 1 || !reflect.DeepEqual(got[0], c.want) {
+				t.Errorf("err: want (%v), got (%v)", c.want, got)
+			}
+		})
+	}
+}
+
+func TestDecodeMouseIncomplete(t *testing.T) {
+	for _, input := range []string{"\x1b[M", "\x1b[M ", "\x1b[M *", "\x1b[<0;10;5"} {
+		if ev, n := decode([]byte(input), false); n != 0 {
+			t.Errorf("err: %q: want incomplete, got (%v, %d)", input, ev, n)
+		}
+	}
+}
+
+func TestMouseString(t *testing.T) {
+	m := Mouse{Button: MouseLeft, Action: MouseRelease, X: 3, Y: 4, Mods: ModCtrl}
+	if got, want := m.String(), "Ctrl+Left Release at 3,4"; got != want {
+		t.Errorf("err: want (%q), got (%q)", want, got)
+	}
+}
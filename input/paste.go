@@ -0,0 +1,76 @@
+package input
+
+import "bytes"
+
+// DefaultPasteLimit is the most a Paste holds, unless told otherwise (see
+// Reader.SetPasteLimit).
+const DefaultPasteLimit = 1 << 20
+
+// Paste is some text pasted with bracketed paste enabled (see termy's
+// Display.EnableBracketedPaste), delivered in one go.
+// Nothing inside is decoded: newlines and escape sequences are just text.
+// Most terminals send line breaks as "\r".
+type Paste struct {
+	Text string
+	// Truncated is set when the paste didn't fit the limit: the rest is dropped.
+	Truncated bool
+}
+
+func (Paste) event() {}
+
+// pasteStart is ESC [ 200 ~, sent before the pasted text. It's handled by the
+// Reader and never returned.
+type pasteStart struct{}
+
+func (pasteStart) event() {}
+
+// pasteEnd is sent after the pasted text.
+var pasteEnd = []byte("\x1b[201~")
+
+// SetPasteLimit sets the most a Paste holds, in bytes. Anything beyond is
+// dropped, but it's still read, so it's not taken for keys.
+func (r *Reader) SetPasteLimit(n int) {
+	r.pasteLimit = max(n, 0)
+}
+
+// -------- Internal -------- //
+
+// readPaste takes the pasted text from buf. It returns the Paste once the
+// end is found (or there's nothing else to read).
+func (r *Reader) readPaste() (Paste, bool) {
+	if i := bytes.Index(r.buf, pasteEnd); i >= 0 {
+		r.keepPasted(r.buf[:i])
+		r.buf = r.buf[i+len(pasteEnd):]
+		return r.endPaste(), true
+	}
+	if r.err != nil {
+		r.keepPasted(r.buf)
+		r.buf = r.buf[:0]
+		return r.endPaste(), true
+	}
+
+	// Keep what could be the beginning of the end.
+	n := max(len(r.buf)-len(pasteEnd)+1, 0)
+	r.keepPasted(r.buf[:n])
+	r.buf = r.buf[n:]
+	return Paste{}, false
+}
+
+// keepPasted adds b to the paste, as long as it fits.
+func (r *Reader) keepPasted(b []byte) {
+	room := r.pasteLimit - len(r.paste)
+	if len(b) > room {
+		b = b[:room]
+		r.truncated = true
+	}
+	r.paste = append(r.paste, b...)
+}
+
+// endPaste returns the Paste and gets ready for the next one.
+func (r *Reader) endPaste() Paste {
+	p := Paste{Text: string(r.paste), Truncated: r.truncated}
+	r.pasting = false
+	r.paste = r.paste[:0]
+	r.truncated = false
+	return p
+}
@@ -0,0 +1,87 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReaderPaste(t *testing.T) {
+	// Given
+	cases := []struct {
+		name   string
+		chunks []string
+		limit  int
+		want   []Event
+	}{
+		{
+			name:   "One read",
+			chunks: []string{"a\x1b[200~hello\rworld\x1b[201~b"},
+			want: []Event{
+				Key{Code: KeyRune, Rune: 'a'},
+				Paste{Text: "hello\rworld"},
+				Key{Code: KeyRune, Rune: 'b'},
+			},
+		},
+		{
+			name:   "Escapes are text",
+			chunks: []string{"\x1b[200~\x1b[A\x03\x1b\x1b[201~"},
+			want:   []Event{Paste{Text: "\x1b[A\x03\x1b"}},
+		},
+		{
+			name:   "Split everywhere",
+			chunks: []string{"\x1b[2", "00~ab", "c\x1b", "[20", "1", "~\x1b[A"},
+			want:   []Event{Paste{Text: "abc"}, Key{Code: KeyUp}},
+		},
+		{
+			name:   "Empty",
+			chunks: []string{"\x1b[200~\x1b[201~"},
+			want:   []Event{Paste{}},
+		},
+		{
+			name:   "Two pastes",
+			chunks: []string{"\x1b[200~1\x1b[201~\x1b[200~2\x1b[201~"},
+			want:   []Event{Paste{Text: "1"}, Paste{Text: "2"}},
+		},
+		{
+			name:   "Over the limit",
+			chunks: []string{"\x1b[200~abc", "def\x1b[201~x"},
+			limit:  4,
+			want:   []Event{Paste{Text: "abcd", Truncated: true}, Key{Code: KeyRune, Rune: 'x'}},
+		},
+		{
+			name:   "Unfinished",
+			chunks: []string{"\x1b[200~abc"},
+			want:   []Event{Paste{Text: "abc"}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := NewReader(&chunkReader{chunks: c.chunks})
+			if c.limit > 0 {
+				r.SetPasteLimit(c.limit)
+			}
+			got := readAll(t, r)
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("err: want (%v), got (%v)", c.want, got)
+			}
+		})
+	}
+}
+
+func TestReaderPasteNoTimeout(t *testing.T) {
+	// A slow paste is still a paste, even if it stops right after an ESC.
+	src := newTimedReader(
+		timedChunk{0, "\x1b[200~a\x1b"},
+		timedChunk{time.Second, "b\x1b[201~"},
+	)
+	r := NewReader(src)
+	r.SetClock(src)
+
+	got := readAll(t, r)
+	want := []Event{Paste{Text: "a\x1bb"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("err: want (%v), got (%v)", want, got)
+	}
+}
@@ -0,0 +1,224 @@
+package input
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultEscTimeout is how long a Reader waits for the rest of a sequence
+// before giving up, i.e. to tell Escape from the start of KeyUp.
+const DefaultEscTimeout = 50 * time.Millisecond
+
+// Waiter is implemented by sources that can wait for input with a timeout,
+// like term.Settings.
+type Waiter interface {
+	// WaitInput waits up to "d" for some input, reporting whether there is any.
+	WaitInput(d time.Duration) (bool, error)
+}
+
+// cancelCheck is how often a Reader waiting on a Waiter checks whether
+// the read was cancelled (see ReadEventContext).
+const cancelCheck = 50 * time.Millisecond
+
+// Clock tells the time. It's here so the timeouts can be tested.
+type Clock interface {
+	Now() time.Time
+}
+
+// Reader reads events from the terminal (or any other io.Reader).
+type Reader struct {
+	src io.Reader
+	// buf holds the bytes read but not decoded yet.
+	buf   []byte
+	chunk []byte
+	// err is the error returned by src, reported once buf is empty.
+	err error
+
+	// wait tells when the rest of a sequence is not coming (see SetWaiter).
+	wait       Waiter
+	clock      Clock
+	escTimeout time.Duration
+	// since is when we started waiting for the rest of buf.
+	since time.Time
+
+	// pasting is set between the start and the end of a bracketed paste.
+	pasting    bool
+	paste      []byte
+	pasteLimit int
+	truncated  bool
+
+	// queries are waiting for the terminal's replies (see Intercept).
+	queries interceptors
+
+	// kitty is set while the kitty keyboard protocol is on (see SetKittyKeyboard).
+	kitty atomic.Bool
+}
+
+// NewReader creates a Reader that reads from src.
+// If src is a Waiter, it's used to tell a lone Escape (see SetWaiter).
+func NewReader(src io.Reader) *Reader {
+	r := &Reader{
+		src:        src,
+		chunk:      make([]byte, 256),
+		clock:      realClock{},
+		escTimeout: DefaultEscTimeout,
+		pasteLimit: DefaultPasteLimit,
+	}
+	if w, ok := src.(Waiter); ok {
+		r.wait = w
+	}
+	return r
+}
+
+// SetWaiter sets what tells whether there's more input coming.
+// With a Waiter, an incomplete sequence (i.e. a lone ESC) is decoded as it is
+// if nothing else arrives within the timeout (see SetEscTimeout): that's
+// Escape, while ESC followed by a key in time is Alt plus that key.
+// Without one, the Reader waits for the next byte to decide.
+// For a terminal, use its term.Settings.
+func (r *Reader) SetWaiter(w Waiter) {
+	r.wait = w
+}
+
+// SetEscTimeout sets how long to wait for the rest of a sequence.
+// The default is DefaultEscTimeout. Zero means no waiting at all: only what's
+// already been typed counts.
+func (r *Reader) SetEscTimeout(d time.Duration) {
+	r.escTimeout = max(d, 0)
+}
+
+// SetKittyKeyboard tells the Reader whether the kitty keyboard protocol is
+// on, so the modifiers of the keys still sent the legacy way (i.e.
+// ESC [ 1 ; 9 A) are decoded as kitty does: 8 is Super, not Meta.
+// It's safe to call while another goroutine reads.
+func (r *Reader) SetKittyKeyboard(on bool) {
+	r.kitty.Store(on)
+}
+
+// SetClock replaces the clock used for timeouts.
+func (r *Reader) SetClock(c Clock) {
+	r.clock = c
+}
+
+// ReadEvent returns the next event, blocking until there is one.
+// Sequences split across reads are put back together, and so are pastes
+// (see Paste).
+// When src fails (i.e. io.EOF), whatever is left is decoded as best as we
+// can, and then the error is returned.
+func (r *Reader) ReadEvent() (Event, error) {
+	return r.ReadEventContext(context.Background())
+}
+
+// ReadEventContext is like ReadEvent, but it gives up when ctx is done,
+// returning its error. Nothing is lost: the next read carries on.
+// Only a Reader with a Waiter (see SetWaiter) can give up while waiting for
+// input: otherwise it's stuck until the read in progress returns.
+func (r *Reader) ReadEventContext(ctx context.Context) (Event, error) {
+	for {
+		if r.pasting {
+			// Pasted text doesn't time out: it's on its way.
+			if p, ok := r.readPaste(); ok {
+				return p, nil
+			}
+		} else {
+			if ev, n := decode(r.buf, r.err != nil); n > 0 {
+				if ev, ok := r.take(ev, n); ok {
+					return ev, nil
+				}
+				continue
+			}
+			if r.err != nil {
+				return nil, r.err
+			}
+
+			if len(r.buf) > 0 && r.wait != nil {
+				more, err := r.waitMore()
+				if err != nil {
+					r.err = err
+					continue
+				}
+				if !more {
+					// It's not coming: make the best of what we have.
+					if ev, ok := r.take(decode(r.buf, true)); ok {
+						return ev, nil
+					}
+					continue
+				}
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := r.waitInput(ctx); err != nil {
+			return nil, err
+		}
+		n, err := r.src.Read(r.chunk)
+		r.buf = append(r.buf, r.chunk[:n]...)
+		r.err = err
+	}
+}
+
+// -------- Internal -------- //
+
+// take consumes the event decoded from the first "n" bytes of buf.
+// Replies to queries are not returned, they're handed to whoever is waiting.
+// Neither is the start of a paste, it just gets the paste going.
+func (r *Reader) take(ev Event, n int) (Event, bool) {
+	if r.queries.intercept(r.buf[:n]) {
+		r.consume(nil, n)
+		return nil, false
+	}
+	if r.kitty.Load() {
+		ev = kittyLegacyKey(ev, r.buf[:n])
+	}
+	ev = r.consume(ev, n)
+	if _, ok := ev.(pasteStart); ok {
+		r.pasting = true
+		return nil, false
+	}
+	return ev, true
+}
+
+// waitInput waits until there's input to read or ctx is done.
+func (r *Reader) waitInput(ctx context.Context) error {
+	if r.wait == nil || ctx.Done() == nil {
+		return nil
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		ready, err := r.wait.WaitInput(cancelCheck)
+		if ready || err != nil {
+			// Let the read tell what's wrong.
+			return nil
+		}
+	}
+}
+
+// waitMore waits for the rest of an incomplete sequence, for whatever is left
+// of the timeout.
+func (r *Reader) waitMore() (bool, error) {
+	now := r.clock.Now()
+	if r.since.IsZero() {
+		r.since = now
+	}
+	left := max(r.escTimeout-now.Sub(r.since), 0)
+	return r.wait.WaitInput(left)
+}
+
+// consume drops the "n" bytes ev was decoded from.
+func (r *Reader) consume(ev Event, n int) Event {
+	r.buf = r.buf[n:]
+	r.since = time.Time{}
+	return ev
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,272 @@
+package input
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// chunkReader returns its chunks one read at a time.
+type chunkReader struct {
+	chunks []string
+}
+
+func (c *chunkReader) Read(p []byte) (int, error) {
+	if len(c.chunks) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, c.chunks[0])
+	c.chunks = c.chunks[1:]
+	return n, nil
+}
+
+// readAll reads events until the reader fails.
+func readAll(t *testing.T, r *Reader) []Event {
+	t.Helper()
+
+	events := []Event{}
+	for {
+		ev, err := r.ReadEvent()
+		if err == io.EOF {
+			return events
+		}
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		events = append(events, ev)
+	}
+}
+
+func TestReaderSplitSequences(t *testing.T) {
+	// Given
+	cases := []struct {
+		name   string
+		chunks []string
+		want   []Event
+	}{
+		{
+			name:   "CSI",
+			chunks: []string{"\x1b", "[", "1", "5", "~"},
+			want:   []Event{Key{Code: KeyF5}},
+		},
+		{
+			name:   "SS3",
+			chunks: []string{"x\x1bO", "Pz"},
+			want:   []Event{Key{Code: KeyRune, Rune: 'x'}, Key{Code: KeyF1}, Key{Code: KeyRune, Rune: 'z'}},
+		},
+		{
+			name:   "UTF-8",
+			chunks: []string{"\xf0\x9f", "\xa9\xb7"},
+			want:   []Event{Key{Code: KeyRune, Rune: '🩷'}},
+		},
+		{
+			name:   "Alt",
+			chunks: []string{"\x1b", "a"},
+			want:   []Event{Key{Code: KeyRune, Rune: 'a', Mods: ModAlt}},
+		},
+		{
+			name:   "Escape at the end",
+			chunks: []string{"a", "\x1b"},
+			want:   []Event{Key{Code: KeyRune, Rune: 'a'}, Key{Code: KeyEscape}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := NewReader(&chunkReader{chunks: c.chunks})
+			got := readAll(t, r)
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("err: want (%v), got (%v)", c.want, got)
+			}
+		})
+	}
+}
+
+// TestReaderUnknownCopy keeps an Unknown (and appends to it) while reading on.
+func TestReaderUnknownCopy(t *testing.T) {
+	// Given
+	up := Key{Code: KeyUp}
+	x, y, z := Key{Code: KeyRune, Rune: 'x'}, Key{Code: KeyRune, Rune: 'y'}, Key{Code: KeyRune, Rune: 'z'}
+	cases := []struct {
+		name string
+		in   string
+		seq  string
+		rest []Event
+	}{
+		{name: "SS3", in: "\x1bOZxyz", seq: "\x1bOZ", rest: []Event{x, y, z}},
+		{name: "Linux console", in: "\x1b[[Zxyz", seq: "\x1b[[Z", rest: []Event{x, y, z}},
+		{name: "Invalid CSI", in: "\x1b[1\x1b[Axyz", seq: "\x1b[1", rest: []Event{up, x, y, z}},
+		{name: "Legacy mouse", in: "\x1b[M\x1b[Axyz", seq: "\x1b[M", rest: []Event{up, x, y, z}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := NewReader(&chunkReader{chunks: []string{c.in}})
+			ev, err := r.ReadEvent()
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			u, ok := ev.(Unknown)
+			if !ok {
+				t.Fatalf("err: want (Unknown), got (%v)", ev)
+			}
+			_ = append(u.Seq, '!')
+
+			if got := readAll(t, r); !reflect.DeepEqual(got, c.rest) {
+				t.Errorf("err: want (%v), got (%v)", c.rest, got)
+			}
+			if got := string(u.Seq); got != c.seq {
+				t.Errorf("err: want (%q), got (%q)", c.seq, got)
+			}
+		})
+	}
+}
+
+// timedChunk is some input that arrives "at" after the start.
+type timedChunk struct {
+	at   time.Duration
+	data string
+}
+
+// timedReader delivers its chunks on time, on a fake clock that only moves
+// while waiting for them.
+type timedReader struct {
+	now    time.Time
+	chunks []timedChunk
+}
+
+func newTimedReader(chunks ...timedChunk) *timedReader {
+	return &timedReader{now: time.Unix(0, 0), chunks: chunks}
+}
+
+func (r *timedReader) Now() time.Time {
+	return r.now
+}
+
+func (r *timedReader) WaitInput(d time.Duration) (bool, error) {
+	deadline := r.now.Add(d)
+	if len(r.chunks) == 0 {
+		r.now = deadline
+		return false, nil
+	}
+	at := time.Unix(0, 0).Add(r.chunks[0].at)
+	if at.After(deadline) {
+		r.now = deadline
+		return false, nil
+	}
+	if at.After(r.now) {
+		r.now = at
+	}
+	return true, nil
+}
+
+func (r *timedReader) Read(p []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, io.EOF
+	}
+	// Block until it arrives.
+	if at := time.Unix(0, 0).Add(r.chunks[0].at); at.After(r.now) {
+		r.now = at
+	}
+	n := copy(p, r.chunks[0].data)
+	r.chunks = r.chunks[1:]
+	return n, nil
+}
+
+func TestReaderEscTimeout(t *testing.T) {
+	ms := time.Millisecond
+
+	// Given
+	cases := []struct {
+		name    string
+		timeout time.Duration
+		chunks  []timedChunk
+		want    []Event
+	}{
+		{
+			name:    "Sequence in time",
+			timeout: 50 * ms,
+			chunks:  []timedChunk{{0, "\x1b"}, {10 * ms, "[A"}},
+			want:    []Event{Key{Code: KeyUp}},
+		},
+		{
+			name:    "Sequence in bits",
+			timeout: 50 * ms,
+			chunks:  []timedChunk{{0, "\x1b"}, {20 * ms, "["}, {40 * ms, "A"}},
+			want:    []Event{Key{Code: KeyUp}},
+		},
+		{
+			name:    "Lone escape",
+			timeout: 50 * ms,
+			chunks:  []timedChunk{{0, "\x1b"}, {500 * ms, "j"}},
+			want:    []Event{Key{Code: KeyEscape}, Key{Code: KeyRune, Rune: 'j'}},
+		},
+		{
+			name:    "Alt in time",
+			timeout: 50 * ms,
+			chunks:  []timedChunk{{0, "\x1b"}, {49 * ms, "j"}},
+			want:    []Event{Key{Code: KeyRune, Rune: 'j', Mods: ModAlt}},
+		},
+		{
+			name:    "Escape then arrows",
+			timeout: 50 * ms,
+			chunks:  []timedChunk{{0, "\x1b"}, {100 * ms, "\x1b[B"}},
+			want:    []Event{Key{Code: KeyEscape}, Key{Code: KeyDown}},
+		},
+		{
+			name:    "Timeout counts from the start",
+			timeout: 50 * ms,
+			chunks:  []timedChunk{{0, "\x1b"}, {30 * ms, "["}, {60 * ms, "A"}},
+			want:    []Event{Key{Code: KeyRune, Rune: '[', Mods: ModAlt}, Key{Code: KeyRune, Rune: 'A'}},
+		},
+		{
+			name:    "Zero timeout",
+			timeout: 0,
+			chunks:  []timedChunk{{0, "\x1b"}, {0, "j"}, {1 * ms, "\x1b"}, {2 * ms, "j"}},
+			want: []Event{
+				Key{Code: KeyRune, Rune: 'j', Mods: ModAlt},
+				Key{Code: KeyEscape},
+				Key{Code: KeyRune, Rune: 'j'},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			src := newTimedReader(c.chunks...)
+			r := NewReader(src)
+			r.SetClock(src)
+			r.SetEscTimeout(c.timeout)
+
+			got := readAll(t, r)
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("err: want (%v), got (%v)", c.want, got)
+			}
+		})
+	}
+}
+
+// idle is a source with no input, ever.
+type idle struct{}
+
+func (idle) Read(p []byte) (int, error) {
+	select {}
+}
+
+func (idle) WaitInput(d time.Duration) (bool, error) {
+	time.Sleep(d)
+	return false, nil
+}
+
+func TestReadEventContext(t *testing.T) {
+	r := NewReader(idle{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if ev, err := r.ReadEventContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("err: want (%v), got (%v, %v)", context.DeadlineExceeded, ev, err)
+	}
+}
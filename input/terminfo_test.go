@@ -0,0 +1,115 @@
+package input
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// terminfoKeys maps the key capabilities in esc_codes to the keys we expect.
+// The function keys beyond F12 (f13 to f63) are generated in terminfoFKey.
+var terminfoKeys = map[string]Key{
+	"b2":        {Code: KeyBegin},
+	"backspace": {Code: KeyBackspace},
+	"btab":      {Code: KeyBacktab},
+	"dc":        {Code: KeyDelete},
+	"down":      {Code: KeyDown},
+	"end":       {Code: KeyEnd},
+	"enter":     {Code: KeyEnter},
+	"home":      {Code: KeyHome},
+	"ic":        {Code: KeyInsert},
+	"left":      {Code: KeyLeft},
+	"npage":     {Code: KeyPgDn},
+	"ppage":     {Code: KeyPgUp},
+	"right":     {Code: KeyRight},
+	"up":        {Code: KeyUp},
+	"sdc":       {Code: KeyDelete, Mods: ModShift},
+	"send":      {Code: KeyEnd, Mods: ModShift},
+	"sf":        {Code: KeyDown, Mods: ModShift},
+	"shome":     {Code: KeyHome, Mods: ModShift},
+	"sic":       {Code: KeyInsert, Mods: ModShift},
+	"sleft":     {Code: KeyLeft, Mods: ModShift},
+	"snext":     {Code: KeyPgDn, Mods: ModShift},
+	"sprevious": {Code: KeyPgUp, Mods: ModShift},
+	"sr":        {Code: KeyUp, Mods: ModShift},
+	"sright":    {Code: KeyRight, Mods: ModShift},
+}
+
+// terminfoSkip are key capabilities that are not keys.
+var terminfoSkip = map[string]bool{
+	"mouse": true,
+}
+
+// terminfoFKey returns the key for the terminfo function key fn.
+// xterm reports F13 onwards as F1-F12 with modifiers, in groups of twelve.
+func terminfoFKey(n int) Key {
+	groups := []Mod{0, ModShift, ModCtrl, ModCtrl | ModShift, ModAlt, ModAlt | ModShift}
+	n--
+	return Key{Code: KeyF1 + KeyCode(n%12), Mods: groups[n/12]}
+}
+
+// unescape expands the terminfo escapes used in esc_codes (\E and ^X).
+func unescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && s[i+1] == 'E':
+			b.WriteByte(esc)
+			i++
+		case s[i] == '^' && i+1 < len(s):
+			b.WriteByte(s[i+1] & 0x1f)
+			i++
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+func TestTerminfoKeys(t *testing.T) {
+	f, err := os.Open("../esc_codes")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer f.Close()
+
+	// Commented out capabilities are still valid, they're just implemented elsewhere.
+	line := regexp.MustCompile(`^#?key_(\w+)=(.*),$`)
+	fkey := regexp.MustCompile(`^f(\d+)$`)
+
+	found := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		m := line.FindStringSubmatch(scanner.Text())
+		if m == nil || terminfoSkip[m[1]] {
+			continue
+		}
+		name, seq := m[1], unescape(m[2])
+
+		want, ok := terminfoKeys[name]
+		if fm := fkey.FindStringSubmatch(name); fm != nil {
+			n, _ := strconv.Atoi(fm[1])
+			want, ok = terminfoFKey(n), true
+		}
+
+		t.Run(name, func(t *testing.T) {
+			if !ok {
+				t.Fatalf("err: no expected key for key_%s (%q)", name, seq)
+			}
+			got := decodeAll([]byte(seq))
+			if len(got) != 1 || got[0] != want {
+				t.Errorf("err: %q: want (%v), got (%v)", seq, want, got)
+			}
+		})
+		found++
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if found == 0 {
+		t.Error("err: no key capabilities found in esc_codes")
+	}
+}
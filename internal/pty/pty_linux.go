@@ -0,0 +1,50 @@
+// Package pty opens pseudo terminal pairs.
+// It's meant to be used by tests that need a real terminal to talk to.
+package pty
+
+import (
+	"os"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+// Open returns a new pseudo terminal pair.
+// Whatever is written to master is seen as input by slave and vice versa.
+// Both files are in blocking mode, so reads behave as they would on a real terminal.
+func Open() (master, slave *os.File, err error) {
+	mfd, err := unix.Open("/dev/ptmx", unix.O_RDWR|unix.O_NOCTTY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Unlock the slave and find out its number.
+	err = unix.IoctlSetPointerInt(mfd, unix.TIOCSPTLCK, 0)
+	if err != nil {
+		unix.Close(mfd)
+		return nil, nil, err
+	}
+	n, err := unix.IoctlGetInt(mfd, unix.TIOCGPTN)
+	if err != nil {
+		unix.Close(mfd)
+		return nil, nil, err
+	}
+
+	name := "/dev/pts/" + strconv.Itoa(n)
+	sfd, err := unix.Open(name, unix.O_RDWR|unix.O_NOCTTY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		unix.Close(mfd)
+		return nil, nil, err
+	}
+
+	return os.NewFile(uintptr(mfd), "/dev/ptmx"), os.NewFile(uintptr(sfd), name), nil
+}
+
+// SetSize changes the window size of the terminal.
+func SetSize(f *os.File, rows, cols int) error {
+	ws := unix.Winsize{
+		Row: uint16(rows),
+		Col: uint16(cols),
+	}
+	return unix.IoctlSetWinsize(int(f.Fd()), unix.TIOCSWINSZ, &ws)
+}
@@ -1,5 +1,7 @@
 package termy
 
+// Raw sequences sent by some keys.
+// To decode the input into key presses, see Display.ReadEvent and the input package.
 const (
 	KeyEnd   = _esc + "OF"
 	KeyEnter = _esc + "OM"
@@ -0,0 +1,89 @@
+package termy
+
+import (
+	"strconv"
+
+	"github.com/mec-nyan/termy/input"
+)
+
+// Kitty keyboard protocol.
+// See https://sw.kovidgoyal.net/kitty/keyboard-protocol/
+
+// PushKeyboardFlags enables the given kitty keyboard enhancements, saving the
+// ones in use on the terminal's stack. Terminals that don't support the
+// protocol just ignore it.
+// Meanwhile, the modifiers of the keys are read as kitty sends them: i.e.
+// Super is 8, which is Meta for xterm.
+// Whatever is pushed is popped on Restore.
+func (d *Display) PushKeyboardFlags(flags input.KittyFlags) {
+	d.write(_csi + ">" + strconv.Itoa(int(flags)) + "u")
+
+	d.mu.Lock()
+	d.kitty = append(d.kitty, flags)
+	d.mu.Unlock()
+
+	d.kittyInput()
+
+	d.enter(kittyKeyboard,
+		func() {
+			d.popKitty(len(d.pushedKitty()))
+			d.input().SetKittyKeyboard(false)
+		},
+		func() {
+			for _, f := range d.pushedKitty() {
+				d.write(_csi + ">" + strconv.Itoa(int(f)) + "u")
+			}
+			d.kittyInput()
+		},
+	)
+}
+
+// PopKeyboardFlags goes back to the flags in use before the last n pushes.
+// Popping more than what was pushed just pops everything.
+func (d *Display) PopKeyboardFlags(n int) {
+	pushed := len(d.pushedKitty())
+	n = min(n, pushed)
+	if n <= 0 {
+		return
+	}
+
+	d.popKitty(n)
+
+	d.mu.Lock()
+	d.kitty = d.kitty[:pushed-n]
+	d.mu.Unlock()
+	d.kittyInput()
+
+	if n == pushed {
+		d.leave(kittyKeyboard)
+	}
+}
+
+// QueryKeyboardFlags asks the terminal for the kitty keyboard flags in use.
+// The answer arrives as an input.KeyboardFlags event. Terminals that don't
+// support the protocol don't answer at all.
+func (d *Display) QueryKeyboardFlags() {
+	d.write(_csi + "?u")
+}
+
+// -------- Internal -------- //
+
+// popKitty pops n entries off the terminal's stack of keyboard flags.
+func (d *Display) popKitty(n int) {
+	d.write(_csi + "<" + strconv.Itoa(n) + "u")
+}
+
+// kittyInput tells the input whether the kitty protocol is on, that is,
+// whether the last flags pushed enable anything (see input.Reader.SetKittyKeyboard).
+func (d *Display) kittyInput() {
+	pushed := d.pushedKitty()
+	d.input().SetKittyKeyboard(len(pushed) > 0 && pushed[len(pushed)-1] != 0)
+}
+
+// pushedKitty returns a copy of the keyboard flags we've pushed.
+func (d *Display) pushedKitty() []input.KittyFlags {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]input.KittyFlags{}, d.kitty...)
+}
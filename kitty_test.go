@@ -0,0 +1,89 @@
+package termy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mec-nyan/termy/input"
+)
+
+func TestKeyboardFlags(t *testing.T) {
+	var out bytes.Buffer
+	d, err := NewDisplayWith(WithOutput(&out), WithoutTermios(), WithEscapes(true))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	d.PushKeyboardFlags(input.KittyDisambiguate)
+	d.PushKeyboardFlags(input.KittyDisambiguate | input.KittyReportEvents)
+	d.QueryKeyboardFlags()
+	d.PopKeyboardFlags(1)
+	if err := d.Restore(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	// Nothing left to pop.
+	d.PopKeyboardFlags(1)
+	if err := d.Restore(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	want := "\x1b[>1u" + "\x1b[>3u" + "\x1b[?u" + "\x1b[<1u" + "\x1b[<1u"
+	if got := out.String(); got != want {
+		t.Errorf("err: want (%q), got (%q)", want, got)
+	}
+}
+
+func TestKeyboardFlagsPopAll(t *testing.T) {
+	var out bytes.Buffer
+	d, err := NewDisplayWith(WithOutput(&out), WithoutTermios(), WithEscapes(true))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	d.PushKeyboardFlags(input.KittyDisambiguate)
+	d.PushKeyboardFlags(input.KittyReportAll)
+	d.PopKeyboardFlags(5)
+	if err := d.Restore(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	want := "\x1b[>1u" + "\x1b[>8u" + "\x1b[<2u"
+	if got := out.String(); got != want {
+		t.Errorf("err: want (%q), got (%q)", want, got)
+	}
+}
+
+func TestKeyboardFlagsModifiers(t *testing.T) {
+	var out bytes.Buffer
+	d, err := NewDisplayWith(
+		WithInput(strings.NewReader("\x1b[1;9A"+"\x1b[1;9A"+"\x1b[1;9A")),
+		WithOutput(&out),
+		WithoutTermios(),
+		WithEscapes(true),
+	)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Given
+	steps := []struct {
+		before func()
+		want   input.Mod
+	}{
+		{before: func() {}, want: input.ModMeta},
+		{before: func() { d.PushKeyboardFlags(input.KittyDisambiguate) }, want: input.ModSuper},
+		{before: func() { d.PopKeyboardFlags(1) }, want: input.ModMeta},
+	}
+
+	for _, step := range steps {
+		step.before()
+		ev, err := d.ReadEvent()
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if key, ok := ev.(input.Key); !ok || key.Mods != step.want {
+			t.Errorf("err: want (%v), got (%v)", step.want, ev)
+		}
+	}
+}
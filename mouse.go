@@ -0,0 +1,116 @@
+package termy
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// MouseMode is what the terminal reports about the mouse.
+// The reports arrive as input.Mouse events.
+type MouseMode int
+
+const (
+	// MouseX10 reports button presses only.
+	MouseX10 MouseMode = 9
+	// MouseNormal reports presses, releases and the wheel.
+	MouseNormal MouseMode = 1000
+	// MouseButtonEvent reports motion while a button is pressed too (i.e. drags).
+	MouseButtonEvent MouseMode = 1002
+	// MouseAnyEvent reports all motion, with or without buttons.
+	MouseAnyEvent MouseMode = 1003
+)
+
+// MouseEncoding is how the terminal writes the reports.
+type MouseEncoding int
+
+const (
+	// MouseLegacy packs every value in a byte: it can't go beyond column or
+	// row 223, and it doesn't tell which button was released.
+	MouseLegacy MouseEncoding = 0
+	// MouseSGR has none of those problems. Use it unless your terminal doesn't support it.
+	MouseSGR MouseEncoding = 1006
+	// MouseSGRPixels is the same as MouseSGR, but the position is in pixels.
+	MouseSGRPixels MouseEncoding = 1016
+)
+
+// EnableMouse makes the terminal report the mouse, replacing any previous mode.
+// Mouse tracking is disabled on Restore.
+func (d *Display) EnableMouse(mode MouseMode, enc MouseEncoding) error {
+	flag, ok := mouseModeFlag(mode)
+	if !ok {
+		return fmt.Errorf("err: unknown mouse mode %d", mode)
+	}
+	encFlag, ok := mouseEncodingFlag(enc)
+	if !ok {
+		return fmt.Errorf("err: unknown mouse encoding %d", enc)
+	}
+
+	d.DisableMouse()
+	// Set the encoding first, so every report uses it.
+	if encFlag != 0 {
+		d.setMode(encFlag, decset(int(enc)), decrst(int(enc)))
+	}
+	d.setMode(flag, decset(int(mode)), decrst(int(mode)))
+
+	return nil
+}
+
+// DisableMouse stops the mouse reports.
+func (d *Display) DisableMouse() {
+	for _, mode := range []MouseMode{MouseX10, MouseNormal, MouseButtonEvent, MouseAnyEvent} {
+		if flag, _ := mouseModeFlag(mode); d.is(flag) {
+			d.resetMode(flag, decrst(int(mode)))
+		}
+	}
+	for _, enc := range []MouseEncoding{MouseSGR, MouseSGRPixels} {
+		if flag, _ := mouseEncodingFlag(enc); d.is(flag) {
+			d.resetMode(flag, decrst(int(enc)))
+		}
+	}
+}
+
+// MouseEnabled reports whether we told the terminal to report the mouse.
+// NOTE: It will NOT check your emulator state directly.
+func (d *Display) MouseEnabled() bool {
+	return d.is(mouseX10) || d.is(mouseNormal) || d.is(mouseButtonEvent) || d.is(mouseAnyEvent)
+}
+
+// -------- Internal -------- //
+
+func mouseModeFlag(mode MouseMode) (uint, bool) {
+	switch mode {
+	case MouseX10:
+		return mouseX10, true
+	case MouseNormal:
+		return mouseNormal, true
+	case MouseButtonEvent:
+		return mouseButtonEvent, true
+	case MouseAnyEvent:
+		return mouseAnyEvent, true
+	}
+	return 0, false
+}
+
+// mouseEncodingFlag returns the flag for the encoding "enc". The legacy one
+// has no flag, as there's nothing to set.
+func mouseEncodingFlag(enc MouseEncoding) (uint, bool) {
+	switch enc {
+	case MouseLegacy:
+		return 0, true
+	case MouseSGR:
+		return mouseSGR, true
+	case MouseSGRPixels:
+		return mouseSGRPixels, true
+	}
+	return 0, false
+}
+
+// decset returns the sequence that sets the private mode "n" (DECSET).
+func decset(n int) string {
+	return _csi + "?" + strconv.Itoa(n) + "h"
+}
+
+// decrst returns the sequence that resets the private mode "n" (DECRST).
+func decrst(n int) string {
+	return _csi + "?" + strconv.Itoa(n) + "l"
+}
@@ -0,0 +1,91 @@
+package termy
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMouse(t *testing.T) {
+	// Given
+	cases := []struct {
+		name   string
+		action func(*Display) error
+		want   string
+		// disabled is set if the mouse is disabled before Restore.
+		disabled bool
+	}{
+		{
+			name:   "Normal, legacy",
+			action: func(d *Display) error { return d.EnableMouse(MouseNormal, MouseLegacy) },
+			want:   "\x1b[?1000h" + "\x1b[?1000l",
+		},
+		{
+			name:   "Any event, SGR",
+			action: func(d *Display) error { return d.EnableMouse(MouseAnyEvent, MouseSGR) },
+			want:   "\x1b[?1006h\x1b[?1003h" + "\x1b[?1003l\x1b[?1006l",
+		},
+		{
+			name: "Replace the mode",
+			action: func(d *Display) error {
+				d.EnableMouse(MouseX10, MouseSGR)
+				return d.EnableMouse(MouseButtonEvent, MouseSGRPixels)
+			},
+			want: "\x1b[?1006h\x1b[?9h" + "\x1b[?9l\x1b[?1006l" + "\x1b[?1016h\x1b[?1002h" + "\x1b[?1002l\x1b[?1016l",
+		},
+		{
+			name: "Disabled before Restore",
+			action: func(d *Display) error {
+				d.EnableMouse(MouseNormal, MouseSGR)
+				d.DisableMouse()
+				return nil
+			},
+			want:     "\x1b[?1006h\x1b[?1000h" + "\x1b[?1000l\x1b[?1006l",
+			disabled: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out bytes.Buffer
+			d, err := NewDisplayWith(WithOutput(&out), WithoutTermios(), WithEscapes(true))
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+
+			if err := c.action(d); err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			if d.MouseEnabled() == c.disabled {
+				t.Errorf("err: want enabled (%v), got (%v)", !c.disabled, d.MouseEnabled())
+			}
+			if err := d.Restore(); err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			if d.MouseEnabled() {
+				t.Error("err: want the mouse disabled after Restore")
+			}
+
+			if got := out.String(); got != c.want {
+				t.Errorf("err: want (%q), got (%q)", c.want, got)
+			}
+		})
+	}
+}
+
+func TestMouseInvalid(t *testing.T) {
+	var out bytes.Buffer
+	d, err := NewDisplayWith(WithOutput(&out), WithoutTermios(), WithEscapes(true))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if err := d.EnableMouse(MouseMode(42), MouseSGR); err == nil {
+		t.Error("err: want an error for an unknown mode, got nil")
+	}
+	if err := d.EnableMouse(MouseNormal, MouseEncoding(42)); err == nil {
+		t.Error("err: want an error for an unknown encoding, got nil")
+	}
+	if out.Len() != 0 {
+		t.Errorf("err: want no output, got (%q)", out.String())
+	}
+}
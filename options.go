@@ -0,0 +1,244 @@
+package termy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/mec-nyan/termy/colour"
+	"github.com/mec-nyan/termy/printer"
+	"github.com/mec-nyan/termy/term"
+	"github.com/mec-nyan/termy/tty"
+	"github.com/mec-nyan/termy/width"
+)
+
+// Option configures a Display created with NewDisplayWith.
+type Option func(*config) error
+
+// config holds everything the options can change.
+type config struct {
+	in  io.Reader
+	out io.Writer
+	// fd is the file descriptor for the terminal settings (-1 means "use the output's").
+	fd        int
+	noTermios bool
+	altScreen bool
+	raw       bool
+	// profile overrides the detection (see printer.DetectColour), if not nil.
+	profile *colour.Profile
+	// escapes overrides the detection, if not nil.
+	escapes *bool
+	// escTimeout overrides input.DefaultEscTimeout, if not nil.
+	escTimeout *time.Duration
+	// pasteLimit overrides input.DefaultPasteLimit, if not nil.
+	pasteLimit *int
+	zwj        width.ZWJPolicy
+	flush      printer.FlushPolicy
+}
+
+// WithInput reads the user input from r instead of os.Stdin.
+func WithInput(r io.Reader) Option {
+	return func(c *config) error {
+		if r == nil {
+			return errors.New("err: nil input")
+		}
+		c.in = r
+		return nil
+	}
+}
+
+// WithOutput writes to w instead of os.Stdout.
+// If w is not a file, you also need WithFd or WithoutTermios.
+func WithOutput(w io.Writer) Option {
+	return func(c *config) error {
+		if w == nil {
+			return errors.New("err: nil output")
+		}
+		c.out = w
+		return nil
+	}
+}
+
+// WithFd uses the terminal behind the file descriptor fd for the terminal settings
+// (cooked, echo, size, etc.), instead of the one behind the output.
+func WithFd(fd int) Option {
+	return func(c *config) error {
+		if fd < 0 {
+			return fmt.Errorf("err: %d is not a valid file descriptor", fd)
+		}
+		c.fd = fd
+		return nil
+	}
+}
+
+// WithoutTermios doesn't touch the terminal settings at all.
+// Use it when there's no terminal (i.e. you drive the Display from buffers).
+// The methods of term.Settings will fail, except for Restore.
+func WithoutTermios() Option {
+	return func(c *config) error {
+		c.noTermios = true
+		return nil
+	}
+}
+
+// WithAltScreen enters the alternate buffer straight away.
+func WithAltScreen() Option {
+	return func(c *config) error {
+		c.altScreen = true
+		return nil
+	}
+}
+
+// WithRawMode puts the terminal in raw mode straight away (see term.Settings.MakeRaw).
+func WithRawMode() Option {
+	return func(c *config) error {
+		c.raw = true
+		return nil
+	}
+}
+
+// WithColourProfile limits the colours to the given profile.
+func WithColourProfile(p colour.Profile) Option {
+	return func(c *config) error {
+		if !p.Valid() {
+			return fmt.Errorf("err: unknown colour profile %v", p)
+		}
+		c.profile = &p
+		return nil
+	}
+}
+
+// WithEscapes forces escape sequences on or off, instead of deciding
+// based on the output and the environment (see printer.DetectEscapes).
+func WithEscapes(on bool) Option {
+	return func(c *config) error {
+		c.escapes = &on
+		return nil
+	}
+}
+
+// WithEscTimeout sets how long to wait for the rest of a sequence before
+// reading a lone ESC as Escape (see Display.SetEscTimeout).
+func WithEscTimeout(d time.Duration) Option {
+	return func(c *config) error {
+		if d < 0 {
+			return fmt.Errorf("err: %v is not a valid escape timeout", d)
+		}
+		c.escTimeout = &d
+		return nil
+	}
+}
+
+// WithPasteLimit sets the most a paste holds, in bytes (see Display.SetPasteLimit).
+func WithPasteLimit(n int) Option {
+	return func(c *config) error {
+		if n < 0 {
+			return fmt.Errorf("err: %d is not a valid paste limit", n)
+		}
+		c.pasteLimit = &n
+		return nil
+	}
+}
+
+// WithZWJPolicy sets how wide the emoji joined with zero width joiners are
+// taken to be (see printer.Printer.SetZWJPolicy).
+func WithZWJPolicy(p width.ZWJPolicy) Option {
+	return func(c *config) error {
+		if p != width.ZWJJoined && p != width.ZWJSplit {
+			return fmt.Errorf("err: unknown ZWJ policy %v", p)
+		}
+		c.zwj = p
+		return nil
+	}
+}
+
+// WithFlushPolicy keeps the output in a buffer until it's flushed, instead
+// of writing it straight away (see printer.Printer.SetFlushPolicy).
+func WithFlushPolicy(p printer.FlushPolicy) Option {
+	return func(c *config) error {
+		switch p {
+		case printer.FlushImmediately, printer.FlushManually, printer.FlushLines:
+		default:
+			return fmt.Errorf("err: unknown flush policy %v", p)
+		}
+		c.flush = p
+		return nil
+	}
+}
+
+// NewDisplayWith initialise a new Display configured with the given options.
+// Without options, it's the same as NewDisplay.
+// Any problem with the options is reported, nothing falls back silently.
+func NewDisplayWith(opts ...Option) (*Display, error) {
+	c := config{
+		in:  os.Stdin,
+		out: os.Stdout,
+		fd:  -1,
+	}
+	for _, opt := range opts {
+		if err := opt(&c); err != nil {
+			return nil, err
+		}
+	}
+
+	return newDisplay(tty.NewWith(c.in, c.out, os.Stderr), c)
+}
+
+// -------- Internal -------- //
+
+// newDisplay sets up a Display on the given TTY.
+// Unless told otherwise, the terminal settings are those of its output.
+func newDisplay(t tty.TTY, c config) (*Display, error) {
+	if c.raw && c.noTermios {
+		return nil, errors.New("err: raw mode needs the terminal settings")
+	}
+
+	d := &Display{
+		Printer:   printer.NewWith(t),
+		noTermios: c.noTermios,
+	}
+	if c.profile != nil {
+		d.Colour.SetProfile(*c.profile)
+	}
+	if c.escapes != nil {
+		d.SetEscapes(*c.escapes)
+	}
+	if c.escTimeout != nil {
+		d.SetEscTimeout(*c.escTimeout)
+	}
+	if c.pasteLimit != nil {
+		d.SetPasteLimit(*c.pasteLimit)
+	}
+	d.SetZWJPolicy(c.zwj)
+	d.SetFlushPolicy(c.flush)
+
+	if !c.noTermios {
+		fd := c.fd
+		if fd < 0 {
+			var ok bool
+			fd, ok = tty.Fd(t.Stdout)
+			if !ok {
+				return nil, errors.New("err: the output is not a file, use WithFd or WithoutTermios")
+			}
+		}
+
+		settings := term.New(fd)
+		if err := settings.Init(); err != nil {
+			return nil, err
+		}
+		d.Settings = *settings
+	}
+
+	if c.raw {
+		if err := d.MakeRaw(); err != nil {
+			return nil, err
+		}
+	}
+	if c.altScreen {
+		d.EnterAltBuf()
+	}
+
+	return d, nil
+}
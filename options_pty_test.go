@@ -0,0 +1,54 @@
+//go:build linux
+
+package termy
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mec-nyan/termy/internal/pty"
+)
+
+func TestNoColourTerminal(t *testing.T) {
+	for _, key := range []string{"FORCE_COLOR", "CLICOLOR_FORCE", "CLICOLOR", "TERM"} {
+		t.Setenv(key, "")
+	}
+	t.Setenv("NO_COLOR", "1")
+
+	master, slave, err := pty.Open()
+	if err != nil {
+		t.Skipf("can't open a pty: %v", err)
+	}
+	defer master.Close()
+	defer slave.Close()
+
+	d, err := NewDisplayWith(WithInput(slave), WithOutput(slave))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !d.Escapes() {
+		t.Fatal("err: want escape sequences on a terminal, even with NO_COLOR")
+	}
+
+	d.MoveTo(3, 2)
+	d.EnterAltBuf()
+	d.SetFg(1).Bold(true).Send()
+	d.Print("x")
+
+	// Everything but the colour.
+	want := "\x1b[2;3H" + "\x1b[?1049h" + "\x1b[1m" + "x"
+	got := make([]byte, 0, len(want))
+	master.SetReadDeadline(time.Now().Add(time.Second))
+	for len(got) < len(want) {
+		buf := make([]byte, 64)
+		n, err := master.Read(buf)
+		if err != nil {
+			break
+		}
+		got = append(got, buf[:n]...)
+	}
+	if !strings.HasPrefix(string(got), want) {
+		t.Errorf("err: want (%q), got (%q)", want, got)
+	}
+}
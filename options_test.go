@@ -0,0 +1,94 @@
+package termy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mec-nyan/termy/colour"
+	"github.com/mec-nyan/termy/printer"
+	"github.com/mec-nyan/termy/width"
+)
+
+func TestNewDisplayWithErrors(t *testing.T) {
+	var buf bytes.Buffer
+
+	// Given
+	cases := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "Nil input", opts: []Option{WithInput(nil)}},
+		{name: "Nil output", opts: []Option{WithOutput(nil)}},
+		{name: "Invalid fd", opts: []Option{WithFd(-1)}},
+		{name: "Invalid profile", opts: []Option{WithColourProfile(colour.Profile(42))}},
+		{name: "Output not a file", opts: []Option{WithOutput(&buf)}},
+		{name: "Negative escape timeout", opts: []Option{WithEscTimeout(-time.Second)}},
+		{name: "Negative paste limit", opts: []Option{WithPasteLimit(-1)}},
+		{name: "Unknown ZWJ policy", opts: []Option{WithZWJPolicy(width.ZWJPolicy(42))}},
+		{name: "Unknown flush policy", opts: []Option{WithFlushPolicy(printer.FlushPolicy(42))}},
+		{name: "Raw without termios", opts: []Option{WithOutput(&buf), WithoutTermios(), WithRawMode()}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d, err := NewDisplayWith(c.opts...)
+			if err == nil {
+				t.Errorf("err: want an error, got a display (%v)", d)
+			}
+		})
+	}
+}
+
+func TestNewDisplayWithBuffers(t *testing.T) {
+	var out bytes.Buffer
+	d, err := NewDisplayWith(
+		WithInput(strings.NewReader("")),
+		WithOutput(&out),
+		WithoutTermios(),
+		WithAltScreen(),
+		WithColourProfile(colour.ANSI16),
+		WithEscapes(true),
+	)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	d.SetFgRGB(255, 0, 0).Send()
+	d.Print("hi")
+	if err := d.Restore(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	want := "\x1b[?1049h" + "\x1b[0;91m" + "hi" + "\x1b[0m" + "\x1b[?1049l"
+	if got := out.String(); got != want {
+		t.Errorf("err: want (%q), got (%q)", want, got)
+	}
+}
+
+func TestPlainDisplay(t *testing.T) {
+	t.Setenv("FORCE_COLOR", "")
+	t.Setenv("CLICOLOR_FORCE", "")
+
+	var out bytes.Buffer
+	// A buffer is not a terminal.
+	d, err := NewDisplayWith(WithOutput(&out), WithoutTermios())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if d.Escapes() {
+		t.Fatal("err: escapes enabled for a buffer")
+	}
+
+	d.HideCur()
+	d.MoveTo(3, 4)
+	d.Bold(true).SetFg(2).Send()
+	d.Print("just text")
+	d.ClearToEOL()
+	d.Restore()
+
+	if got := out.String(); got != "just text" {
+		t.Errorf("err: want (%q), got (%q)", "just text", got)
+	}
+}
@@ -0,0 +1,167 @@
+package termy
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mec-nyan/termy/colour"
+)
+
+const (
+	// OSC (Operating system command) starts some of the queries.
+	_osc = "\x1b]"
+	// ST (String terminator) ends them.
+	_st = "\x1b\\"
+)
+
+// colourReply is a colour the terminal reported (or why it didn't).
+type colourReply struct {
+	rgb colour.RGB
+	err error
+}
+
+// ForegroundColour asks the terminal for its default text colour (OSC 10).
+// The answer is cached (see ForgetColours). It returns ErrNoReply if the
+// terminal doesn't tell.
+func (d *Display) ForegroundColour() (colour.RGB, error) {
+	return d.termColour("10")
+}
+
+// BackgroundColour asks the terminal for its default background colour (OSC 11).
+// The answer is cached (see ForgetColours). It returns ErrNoReply if the
+// terminal doesn't tell.
+func (d *Display) BackgroundColour() (colour.RGB, error) {
+	return d.termColour("11")
+}
+
+// PaletteColour asks the terminal for the colour "n" (0-255) of its palette,
+// that is, what SetFg(n) looks like (OSC 4).
+// The answer is cached (see ForgetColours).
+func (d *Display) PaletteColour(n int) (colour.RGB, error) {
+	if n < 0 || n > 255 {
+		return colour.RGB{}, fmt.Errorf("err: %d is not a palette colour (0-255)", n)
+	}
+	return d.termColour("4;" + strconv.Itoa(n))
+}
+
+// Palette asks the terminal for the 16 ANSI colours, all at once.
+// The answers are cached (see ForgetColours).
+func (d *Display) Palette() ([16]colour.RGB, error) {
+	var palette [16]colour.RGB
+
+	codes := make([]string, len(palette))
+	for n := range palette {
+		codes[n] = "4;" + strconv.Itoa(n)
+	}
+	if err := d.queryColours(codes...); err != nil {
+		return palette, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for n, code := range codes {
+		reply := d.colours[code]
+		if reply.err != nil {
+			return palette, reply.err
+		}
+		palette[n] = reply.rgb
+	}
+	return palette, nil
+}
+
+// IsDarkBackground tells whether the terminal has a dark background, so you can
+// choose colours that read well on it.
+// If the terminal doesn't report its background, it relies on $COLORFGBG (set
+// by some terminals), and then it just guesses dark, as most terminals are.
+func (d *Display) IsDarkBackground() bool {
+	if bg, err := d.BackgroundColour(); err == nil {
+		return bg.IsDark()
+	}
+	// COLORFGBG is "fg;bg" (or "fg;default;bg"), in palette colours.
+	if fgbg := os.Getenv("COLORFGBG"); fgbg != "" {
+		fields := strings.Split(fgbg, ";")
+		if bg, err := strconv.Atoi(fields[len(fields)-1]); err == nil {
+			return bg < 7 || bg == 8
+		}
+	}
+	return true
+}
+
+// ForgetColours drops the cached colours, so they are asked for again.
+// Use it if the user may have changed the terminal's theme.
+func (d *Display) ForgetColours() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.colours = nil
+}
+
+// -------- Internal -------- //
+
+// termColour returns the colour "code", asking the terminal if it's not cached.
+func (d *Display) termColour(code string) (colour.RGB, error) {
+	if err := d.queryColours(code); err != nil {
+		return colour.RGB{}, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	reply := d.colours[code]
+	return reply.rgb, reply.err
+}
+
+// queryColours asks the terminal for the colours it doesn't know yet, with
+// DA1 as sentinel (see Probe), so unsupported queries don't make us wait.
+// A colour the terminal doesn't tell before the sentinel is cached too, with
+// ErrNoReply: it's not going to change its mind. If the sentinel doesn't
+// arrive either, nothing is cached (the replies may just be late).
+func (d *Display) queryColours(codes ...string) error {
+	d.mu.Lock()
+	missing := []string{}
+	for _, code := range codes {
+		if _, ok := d.colours[code]; !ok {
+			missing = append(missing, code)
+		}
+	}
+	d.mu.Unlock()
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	defer d.replyMode()()
+
+	var request strings.Builder
+	replies := []*regexp.Regexp{}
+	for _, code := range missing {
+		request.WriteString(_osc + code + ";?" + _st)
+		replies = append(replies, regexp.MustCompile(
+			`^\x1b\]`+regexp.QuoteMeta(code)+`;(rgba?:[0-9A-Fa-f/]+)(?:\x07|\x1b\\)$`))
+	}
+	request.WriteString(_csi + "c")
+	replies = append(replies, da1Reply)
+
+	m, err := d.queryAll(request.String(), replies, DefaultQueryTimeout)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.colours == nil {
+		d.colours = map[string]colourReply{}
+	}
+	for i, code := range missing {
+		reply := colourReply{err: ErrNoReply}
+		if m[i] != nil {
+			reply.rgb, reply.err = colour.ParseXColour(m[i][1])
+		}
+		d.colours[code] = reply
+	}
+	return nil
+}
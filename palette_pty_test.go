@@ -0,0 +1,44 @@
+//go:build linux
+
+package termy
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mec-nyan/termy/colour"
+	"github.com/mec-nyan/termy/internal/pty"
+)
+
+func TestColoursNoSentinel(t *testing.T) {
+	master, slave, err := pty.Open()
+	if err != nil {
+		t.Skipf("can't open a pty: %v", err)
+	}
+	defer master.Close()
+	defer slave.Close()
+
+	var out bytes.Buffer
+	d, err := NewDisplayWith(WithInput(slave), WithOutput(&out), WithFd(int(slave.Fd())), WithEscapes(true))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := d.MakeRaw(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Not even the sentinel gets an answer.
+	if _, err := d.BackgroundColour(); err != ErrNoReply {
+		t.Errorf("err: want (%v), got (%v)", ErrNoReply, err)
+	}
+
+	// That's not cached: the terminal is asked again.
+	master.Write([]byte("\x1b]11;rgb:ffff/ffff/ffff\x07\x1b[?62c"))
+	bg, err := d.BackgroundColour()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if want := (colour.RGB{R: 255, G: 255, B: 255}); bg != want {
+		t.Errorf("err: want (%v), got (%v)", want, bg)
+	}
+}
@@ -0,0 +1,101 @@
+package termy
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mec-nyan/termy/colour"
+	"github.com/mec-nyan/termy/input"
+)
+
+// newReplyDisplay returns a Display whose terminal answers with "replies".
+func newReplyDisplay(t *testing.T, replies string) (*Display, *bytes.Buffer) {
+	t.Helper()
+
+	var out bytes.Buffer
+	d, err := NewDisplayWith(WithInput(strings.NewReader(replies)), WithOutput(&out), WithoutTermios(), WithEscapes(true))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return d, &out
+}
+
+func TestBackgroundColour(t *testing.T) {
+	d, out := newReplyDisplay(t, "\x1b]11;rgb:1e1e/1e1e/2e2e\x07\x1b[?62c")
+
+	bg, err := d.BackgroundColour()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if want := (colour.RGB{R: 30, G: 30, B: 46}); bg != want {
+		t.Errorf("err: want (%v), got (%v)", want, bg)
+	}
+	if !d.IsDarkBackground() {
+		t.Error("err: want a dark background")
+	}
+
+	// Asked once, the rest comes from the cache.
+	if got, want := out.String(), "\x1b]11;?\x1b\\\x1b[c"; got != want {
+		t.Errorf("err: want (%q), got (%q)", want, got)
+	}
+}
+
+func TestColoursNotSupported(t *testing.T) {
+	t.Setenv("COLORFGBG", "0;15")
+	// Only the sentinel gets an answer.
+	d, out := newReplyDisplay(t, "\x1b[?62c")
+
+	if _, err := d.ForegroundColour(); err != ErrNoReply {
+		t.Errorf("err: want (%v), got (%v)", ErrNoReply, err)
+	}
+	if d.IsDarkBackground() {
+		t.Error("err: want a light background, from COLORFGBG")
+	}
+	if _, err := d.PaletteColour(256); err == nil {
+		t.Error("err: want an error for colour 256, got nil")
+	}
+
+	// The terminal isn't asked twice.
+	if n := strings.Count(out.String(), "\x1b[c"); n != 2 {
+		t.Errorf("err: want (2) queries, got (%d): %q", n, out.String())
+	}
+}
+
+func TestPalette(t *testing.T) {
+	var replies strings.Builder
+	want := [16]colour.RGB{}
+	for n := 15; n >= 0; n-- {
+		want[n] = colour.RGB{R: n, G: n * 2, B: n * 3}
+		// The terminal answers in its own way, with ST this time.
+		fmt.Fprintf(&replies, "\x1b]4;%d;rgb:%02x/%02x/%02x\x1b\\", n, n, n*2, n*3)
+		if n == 8 {
+			replies.WriteString("k")
+		}
+	}
+	replies.WriteString("\x1b[?62c")
+	d, _ := newReplyDisplay(t, replies.String())
+
+	got, err := d.Palette()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if got != want {
+		t.Errorf("err: want (%v), got (%v)", want, got)
+	}
+
+	// Cached.
+	if c, err := d.PaletteColour(9); err != nil || c != want[9] {
+		t.Errorf("err: want (%v), got (%v, %v)", want[9], c, err)
+	}
+	// The key typed in between is still there.
+	if ev, _ := d.ReadEvent(); ev != (input.Key{Code: input.KeyRune, Rune: 'k'}) {
+		t.Errorf("err: want (k), got (%v)", ev)
+	}
+
+	d.ForgetColours()
+	if _, err := d.PaletteColour(9); err == nil {
+		t.Error("err: want an error asking again with no input, got nil")
+	}
+}
@@ -0,0 +1,27 @@
+package termy
+
+// EnableBracketedPaste makes the terminal mark whatever is pasted, so it
+// arrives as a single input.Paste event instead of a bunch of keys.
+// It's disabled on Restore.
+func (d *Display) EnableBracketedPaste() {
+	d.setMode(bracketedPaste, decset(2004), decrst(2004))
+}
+
+// DisablePaste disables bracketed paste: pasted text arrives as keys again.
+func (d *Display) DisablePaste() {
+	d.resetMode(bracketedPaste, decrst(2004))
+}
+
+// BracketedPaste reports whether we enabled bracketed paste.
+// NOTE: It will NOT check your emulator state directly.
+func (d *Display) BracketedPaste() bool {
+	return d.is(bracketedPaste)
+}
+
+// SetPasteLimit sets the most an input.Paste holds, in bytes: anything beyond
+// is dropped, and the Paste is marked Truncated.
+// The default is input.DefaultPasteLimit.
+func (d *Display) SetPasteLimit(n int) *Display {
+	d.input().SetPasteLimit(n)
+	return d
+}
@@ -0,0 +1,88 @@
+package termy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mec-nyan/termy/input"
+)
+
+func TestBracketedPaste(t *testing.T) {
+	var out bytes.Buffer
+	d, err := NewDisplayWith(
+		WithInput(strings.NewReader("\x1b[200~ls\r\x1b[201~")),
+		WithOutput(&out),
+		WithoutTermios(),
+		WithEscapes(true),
+	)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	d.EnableBracketedPaste()
+	if !d.BracketedPaste() {
+		t.Error("err: want bracketed paste enabled")
+	}
+
+	ev, err := d.ReadEvent()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if want := (input.Paste{Text: "ls\r"}); ev != want {
+		t.Errorf("err: want (%v), got (%v)", want, ev)
+	}
+
+	// Enabling it twice is harmless, and it's undone once.
+	d.EnableBracketedPaste()
+	if err := d.Restore(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if d.BracketedPaste() {
+		t.Error("err: want bracketed paste disabled after Restore")
+	}
+	d.DisablePaste()
+
+	want := "\x1b[?2004h" + "\x1b[?2004h" + "\x1b[?2004l" + "\x1b[?2004l"
+	if got := out.String(); got != want {
+		t.Errorf("err: want (%q), got (%q)", want, got)
+	}
+}
+
+func TestPasteLimit(t *testing.T) {
+	paste := "\x1b[200~" + "hello world" + "\x1b[201~"
+
+	// Given
+	cases := []struct {
+		name string
+		opts []Option
+		set  int
+		want input.Paste
+	}{
+		{name: "Default", want: input.Paste{Text: "hello world"}},
+		{name: "WithPasteLimit", opts: []Option{WithPasteLimit(5)}, want: input.Paste{Text: "hello", Truncated: true}},
+		{name: "SetPasteLimit", set: 3, want: input.Paste{Text: "hel", Truncated: true}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out bytes.Buffer
+			opts := append([]Option{WithInput(strings.NewReader(paste + "x")), WithOutput(&out), WithoutTermios()}, c.opts...)
+			d, err := NewDisplayWith(opts...)
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			if c.set > 0 {
+				d.SetPasteLimit(c.set)
+			}
+
+			if ev, err := d.ReadEvent(); err != nil || ev != c.want {
+				t.Errorf("err: want (%v), got (%v, %v)", c.want, ev, err)
+			}
+			// The rest of the paste isn't taken for keys.
+			if ev, _ := d.ReadEvent(); ev != (input.Key{Code: input.KeyRune, Rune: 'x'}) {
+				t.Errorf("err: want (x), got (%v)", ev)
+			}
+		})
+	}
+}
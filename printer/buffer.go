@@ -0,0 +1,145 @@
+package printer
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// FlushPolicy decides when the buffered output is sent to the terminal.
+type FlushPolicy int
+
+const (
+	// FlushImmediately sends every write straight away, no buffering: one
+	// write (a syscall) each. It's the default.
+	FlushImmediately FlushPolicy = iota
+	// FlushManually keeps the output until Flush, or until there's more than
+	// the threshold (see SetFlushThreshold).
+	FlushManually
+	// FlushLines is like FlushManually, but it also flushes at the end of
+	// every line, like the standard output of a C program.
+	FlushLines
+)
+
+// DefaultFlushThreshold is how much output is kept before flushing it anyway.
+const DefaultFlushThreshold = 32 << 10
+
+// buffer keeps the output until it's flushed.
+// It's shared by the copies of a Printer, and guarded, as the output can be
+// written from several goroutines (i.e. a signal handler restoring the terminal).
+type buffer struct {
+	mu        sync.Mutex
+	buf       []byte
+	policy    FlushPolicy
+	threshold int
+}
+
+// Write writes b to the output, or keeps it for later, as the flush policy
+// says. When it's kept, all of b is taken, even if flushing (when the buffer
+// is full) fails.
+func (p *Printer) Write(b []byte) (int, error) {
+	if p.buffer == nil {
+		return p.Stdout.Write(b)
+	}
+
+	p.buffer.mu.Lock()
+	defer p.buffer.mu.Unlock()
+
+	switch {
+	case p.buffer.policy == FlushImmediately && len(p.buffer.buf) == 0:
+		return p.Stdout.Write(b)
+	case p.buffer.policy == FlushImmediately,
+		p.buffer.policy == FlushLines && bytes.IndexByte(b, '\n') >= 0,
+		len(p.buffer.buf)+len(b) >= p.buffer.threshold:
+		p.buffer.buf = append(p.buffer.buf, b...)
+		return len(b), p.flush()
+	}
+	p.buffer.buf = append(p.buffer.buf, b...)
+	return len(b), nil
+}
+
+// Flush sends everything kept in the buffer to the output.
+// If it fails, what couldn't be written is kept for the next time.
+func (p *Printer) Flush() error {
+	if p.buffer == nil {
+		return nil
+	}
+
+	p.buffer.mu.Lock()
+	defer p.buffer.mu.Unlock()
+
+	return p.flush()
+}
+
+// SetFlushPolicy sets when the output is sent to the terminal.
+// Going back to FlushImmediately flushes what was kept.
+func (p *Printer) SetFlushPolicy(policy FlushPolicy) *Printer {
+	if p.buffer == nil {
+		p.buffer = &buffer{threshold: DefaultFlushThreshold}
+	}
+
+	p.buffer.mu.Lock()
+	defer p.buffer.mu.Unlock()
+
+	p.buffer.policy = policy
+	if policy == FlushImmediately {
+		p.flush()
+	}
+	return p
+}
+
+// FlushPolicy returns when the output is sent to the terminal.
+func (p *Printer) FlushPolicy() FlushPolicy {
+	if p.buffer == nil {
+		return FlushImmediately
+	}
+
+	p.buffer.mu.Lock()
+	defer p.buffer.mu.Unlock()
+
+	return p.buffer.policy
+}
+
+// SetFlushThreshold sets how much output (in bytes) is kept before flushing
+// it anyway. Zero or less means DefaultFlushThreshold.
+func (p *Printer) SetFlushThreshold(n int) *Printer {
+	if n <= 0 {
+		n = DefaultFlushThreshold
+	}
+	if p.buffer == nil {
+		p.buffer = &buffer{}
+	}
+
+	p.buffer.mu.Lock()
+	defer p.buffer.mu.Unlock()
+
+	p.buffer.threshold = n
+	return p
+}
+
+// Buffered returns how many bytes are waiting to be flushed.
+func (p *Printer) Buffered() int {
+	if p.buffer == nil {
+		return 0
+	}
+
+	p.buffer.mu.Lock()
+	defer p.buffer.mu.Unlock()
+
+	return len(p.buffer.buf)
+}
+
+// -------- Internal -------- //
+
+// flush writes out the buffer. The caller holds the lock.
+func (p *Printer) flush() error {
+	if len(p.buffer.buf) == 0 {
+		return nil
+	}
+	n, err := p.Stdout.Write(p.buffer.buf)
+	if err == nil && n < len(p.buffer.buf) {
+		err = io.ErrShortWrite
+	}
+	p.buffer.buf = p.buffer.buf[:copy(p.buffer.buf, p.buffer.buf[n:])]
+	return err
+}
@@ -0,0 +1,157 @@
+package printer
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/mec-nyan/termy/tty"
+)
+
+// countingWriter counts the writes it gets.
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+	// limit makes the writes after it fail, if not zero.
+	limit int
+}
+
+func (w *countingWriter) Write(b []byte) (int, error) {
+	w.writes++
+	if w.limit > 0 && w.Len()+len(b) > w.limit {
+		n, _ := w.Buffer.Write(b[:w.limit-w.Len()])
+		return n, errors.New("full")
+	}
+	return w.Buffer.Write(b)
+}
+
+func TestFlushPolicy(t *testing.T) {
+	// Given
+	cases := []struct {
+		name   string
+		policy FlushPolicy
+		// writes and want are what's written after each Print.
+		writes []int
+		want   []string
+	}{
+		{
+			name:   "Immediately",
+			policy: FlushImmediately,
+			writes: []int{1, 2, 3},
+			want:   []string{"one", "one two\n", "one two\nthree"},
+		},
+		{
+			name:   "Manually",
+			policy: FlushManually,
+			writes: []int{0, 0, 0},
+			want:   []string{"", "", ""},
+		},
+		{
+			name:   "Lines",
+			policy: FlushLines,
+			writes: []int{0, 1, 1},
+			want:   []string{"", "one two\n", "one two\n"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out countingWriter
+			p := NewWith(tty.NewWith(os.Stdin, &out, os.Stderr))
+			p.SetEscapes(false).SetFlushPolicy(c.policy)
+
+			for i, s := range []string{"one", " two\n", "three"} {
+				if n, err := p.Print(s); n != len(s) || err != nil {
+					t.Errorf("err: want (%d, nil), got (%d, %v)", len(s), n, err)
+				}
+				if out.writes != c.writes[i] || out.String() != c.want[i] {
+					t.Errorf("err: %d: want (%q) in %d writes, got (%q) in %d", i, c.want[i], c.writes[i], out.String(), out.writes)
+				}
+			}
+
+			if err := p.Flush(); err != nil {
+				t.Errorf("err: %v", err)
+			}
+			if got, want := out.String(), "one two\nthree"; got != want || p.Buffered() != 0 {
+				t.Errorf("err: want (%q), got (%q) and %d bytes left", want, got, p.Buffered())
+			}
+		})
+	}
+}
+
+func TestFlushThreshold(t *testing.T) {
+	var out countingWriter
+	p := NewWith(tty.NewWith(os.Stdin, &out, os.Stderr))
+	p.SetEscapes(false).SetFlushPolicy(FlushManually).SetFlushThreshold(8)
+
+	p.Print("1234")
+	p.Print("567")
+	if out.writes != 0 || p.Buffered() != 7 {
+		t.Errorf("err: want 7 bytes kept, got (%q) written and %d kept", out.String(), p.Buffered())
+	}
+	p.Print("89")
+	if out.writes != 1 || out.String() != "123456789" {
+		t.Errorf("err: want everything in one write, got (%q) in %d", out.String(), out.writes)
+	}
+
+	// Going back to writing straight away sends what was kept.
+	p.Print("a")
+	p.SetFlushPolicy(FlushImmediately)
+	if out.String() != "123456789a" || p.FlushPolicy() != FlushImmediately {
+		t.Errorf("err: want everything written, got (%q)", out.String())
+	}
+}
+
+func TestFlushError(t *testing.T) {
+	out := countingWriter{limit: 4}
+	p := NewWith(tty.NewWith(os.Stdin, &out, os.Stderr))
+	p.SetEscapes(false).SetFlushPolicy(FlushManually)
+
+	p.Print("123456")
+	if err := p.Flush(); err == nil {
+		t.Error("err: want an error")
+	}
+	// What couldn't be written is still there.
+	if p.Buffered() != 2 {
+		t.Errorf("err: want 2 bytes kept, got %d", p.Buffered())
+	}
+
+	out.limit = 0
+	if err := p.Flush(); err != nil {
+		t.Errorf("err: %v", err)
+	}
+	if got := out.String(); got != "123456" {
+		t.Errorf("err: want (%q), got (%q)", "123456", got)
+	}
+}
+
+func BenchmarkPrinter(b *testing.B) {
+	// Given
+	cases := []struct {
+		name   string
+		policy FlushPolicy
+	}{
+		{name: "Immediately", policy: FlushImmediately},
+		{name: "Manually", policy: FlushManually},
+	}
+
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			var out countingWriter
+			p := NewWith(tty.NewWith(os.Stdin, &out, os.Stderr))
+			p.SetEscapes(true).SetFlushPolicy(c.policy)
+
+			// A line of 80 cells in different colours.
+			for range b.N {
+				for i := range 80 {
+					p.SetFg(i % 8).Send()
+					p.Print("x")
+				}
+				p.Flush()
+				out.Reset()
+			}
+			b.ReportMetric(float64(out.writes)/float64(b.N), "writes/op")
+		})
+	}
+}
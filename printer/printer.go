@@ -8,10 +8,14 @@ Some of it may be useful in the Display object for more simple cases.
 package printer
 
 import (
+	"io"
+	"os"
+
 	"github.com/mec-nyan/termy/byteme"
 	"github.com/mec-nyan/termy/colour"
 	"github.com/mec-nyan/termy/style"
 	"github.com/mec-nyan/termy/tty"
+	"github.com/mec-nyan/termy/width"
 )
 
 // Printer prints to the writer with selected colour and style.
@@ -19,14 +23,86 @@ type Printer struct {
 	colour.Colour
 	style.Style
 	tty.TTY
+	// plain is set when we shouldn't send escape sequences, only text.
+	plain bool
+	// measure tells how many columns the text takes (see PrintNBytes).
+	measure width.Measure
+	// buffer keeps the output until it's flushed (see SetFlushPolicy).
+	// Without it, everything is written straight away.
+	buffer *buffer
 }
 
 func New() Printer {
-	return Printer{
+	return NewWith(tty.New())
+}
+
+// NewWith creates a new Printer that writes to the given TTY.
+// Escape sequences are only sent if the output can handle them (see DetectEscapes),
+// and colours only if the user wants them (see DetectColour).
+func NewWith(t tty.TTY) Printer {
+	p := Printer{
 		Colour: colour.Colour{},
 		Style:  style.Style{},
-		TTY:    tty.New(),
+		TTY:    t,
+		plain:  !DetectEscapes(t.Stdout),
+		buffer: &buffer{threshold: DefaultFlushThreshold},
+	}
+	if !DetectColour() {
+		p.Colour.SetProfile(colour.NoColour)
+	}
+	return p
+}
+
+// DetectEscapes decides whether escape sequences should be written to w.
+// Following the usual conventions, in order:
+//   - FORCE_COLOR or CLICOLOR_FORCE (not empty nor "0") turn them on.
+//   - TERM=dumb turns them off.
+//   - Otherwise, they're on only if w is a terminal.
+//
+// NO_COLOR doesn't turn them off: it's about colours only (see DetectColour).
+func DetectEscapes(w io.Writer) bool {
+	if forceColour() {
+		return true
 	}
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return tty.IsTerminal(w)
+}
+
+// DetectColour decides whether colours should be used, when escape sequences
+// are sent. FORCE_COLOR or CLICOLOR_FORCE (not empty nor "0") turn them on,
+// then NO_COLOR (not empty) or CLICOLOR=0 turn them off.
+// Without colours, the cursor movements, styles, etc. still work.
+func DetectColour() bool {
+	if forceColour() {
+		return true
+	}
+	return os.Getenv("NO_COLOR") == "" && os.Getenv("CLICOLOR") != "0"
+}
+
+// Escapes reports whether escape sequences (colours, styles, etc.) are being sent.
+// When they're not, only the text is printed.
+func (p *Printer) Escapes() bool {
+	return !p.plain
+}
+
+// SetEscapes overrides the decision made by DetectEscapes.
+func (p *Printer) SetEscapes(on bool) *Printer {
+	p.plain = !on
+	return p
+}
+
+// SetZWJPolicy sets how wide the emoji joined with zero width joiners are
+// taken to be, as terminals disagree. The default is width.ZWJJoined.
+func (p *Printer) SetZWJPolicy(policy width.ZWJPolicy) *Printer {
+	p.measure.ZWJ = policy
+	return p
+}
+
+// Measure returns how the Printer measures text, i.e. to align it.
+func (p *Printer) Measure() width.Measure {
+	return p.measure
 }
 
 // Set the foreground colour using the terminal's theme.
@@ -260,16 +336,34 @@ func (p *Printer) escaped() string {
 
 // Send actually sends the in-band signal to the terminal/selected writer.
 func (p *Printer) Send() {
-	p.Stdout.Write(byteme.UnsafeStrToBytes(p.escaped()))
+	if p.plain {
+		return
+	}
+	p.Write(byteme.UnsafeStrToBytes(p.escaped()))
 }
 
 // PrintBytes prints out a slice of bytes with the printer style.
 func (p *Printer) PrintBytes(b []byte) (int, error) {
+	if p.plain {
+		return p.Write(b)
+	}
 	p.Send()
 	// Should we clear at the end?
 	// Maybe not, but we're doing it for now.
 	b = append(b, byteme.UnsafeStrToBytes("\x1b[0m")...)
-	return p.Stdout.Write(b)
+	return p.Write(b)
+}
+
+// PrintNBytes prints as much of b as fits in "cols" columns with the printer
+// style, never splitting a character. It returns how many bytes of b were
+// printed.
+func (p *Printer) PrintNBytes(cols int, b []byte) (int, error) {
+	n, _ := p.measure.Truncate(b, cols)
+	// PrintBytes appends to what it gets: don't let it write over the rest of b.
+	if _, err := p.PrintBytes(b[:n:n]); err != nil {
+		return 0, err
+	}
+	return n, nil
 }
 
 // Print prints a utf-8 encoded string.
@@ -279,3 +373,12 @@ func (p *Printer) Print(s string) (int, error) {
 }
 
 // -------- Internal -------- //
+
+// forceColour reports whether the environment forces colours (and so escapes) on.
+func forceColour() bool {
+	if force := os.Getenv("FORCE_COLOR"); force != "" && force != "0" && force != "false" {
+		return true
+	}
+	force := os.Getenv("CLICOLOR_FORCE")
+	return force != "" && force != "0"
+}
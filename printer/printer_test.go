@@ -0,0 +1,154 @@
+package printer
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/mec-nyan/termy/tty"
+	"github.com/mec-nyan/termy/width"
+)
+
+func TestDetectEscapes(t *testing.T) {
+	// Given
+	cases := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{
+			name: "Not a terminal",
+			want: false,
+		},
+		{
+			name: "FORCE_COLOR",
+			env:  map[string]string{"FORCE_COLOR": "1"},
+			want: true,
+		},
+		{
+			name: "FORCE_COLOR=0",
+			env:  map[string]string{"FORCE_COLOR": "0"},
+			want: false,
+		},
+		{
+			name: "CLICOLOR_FORCE",
+			env:  map[string]string{"CLICOLOR_FORCE": "1"},
+			want: true,
+		},
+		{
+			name: "FORCE_COLOR beats NO_COLOR",
+			env:  map[string]string{"FORCE_COLOR": "1", "NO_COLOR": "1"},
+			want: true,
+		},
+		{
+			name: "NO_COLOR",
+			env:  map[string]string{"NO_COLOR": "1"},
+			want: false,
+		},
+		{
+			name: "CLICOLOR=0",
+			env:  map[string]string{"CLICOLOR": "0"},
+			want: false,
+		},
+		{
+			name: "TERM=dumb",
+			env:  map[string]string{"TERM": "dumb"},
+			want: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			for _, key := range []string{"FORCE_COLOR", "CLICOLOR_FORCE", "NO_COLOR", "CLICOLOR", "TERM"} {
+				t.Setenv(key, c.env[key])
+			}
+
+			got := DetectEscapes(&bytes.Buffer{})
+			if got != c.want {
+				t.Errorf("err: want (%v), got (%v)", c.want, got)
+			}
+		})
+	}
+}
+
+func TestDetectColour(t *testing.T) {
+	// Given
+	cases := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{name: "Default", want: true},
+		{name: "NO_COLOR", env: map[string]string{"NO_COLOR": "1"}, want: false},
+		{name: "CLICOLOR=0", env: map[string]string{"CLICOLOR": "0"}, want: false},
+		{name: "FORCE_COLOR beats NO_COLOR", env: map[string]string{"FORCE_COLOR": "1", "NO_COLOR": "1"}, want: true},
+		{name: "TERM=dumb is about escapes", env: map[string]string{"TERM": "dumb"}, want: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			for _, key := range []string{"FORCE_COLOR", "CLICOLOR_FORCE", "NO_COLOR", "CLICOLOR", "TERM"} {
+				t.Setenv(key, c.env[key])
+			}
+
+			if got := DetectColour(); got != c.want {
+				t.Errorf("err: want (%v), got (%v)", c.want, got)
+			}
+		})
+	}
+}
+
+func TestPlain(t *testing.T) {
+	// Given
+	cases := []struct {
+		name    string
+		escapes bool
+		want    string
+	}{
+		{name: "Escapes", escapes: true, want: "\x1b[1;38:5:1mhi\x1b[0m"},
+		{name: "Plain", escapes: false, want: "hi"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out bytes.Buffer
+			p := NewWith(tty.NewWith(os.Stdin, &out, os.Stderr))
+			p.SetEscapes(c.escapes)
+
+			if p.Escapes() != c.escapes {
+				t.Errorf("err: Escapes: want (%v), got (%v)", c.escapes, p.Escapes())
+			}
+
+			p.Bold(true).SetFg(1).Print("hi")
+			if got := out.String(); got != c.want {
+				t.Errorf("err: want (%q), got (%q)", c.want, got)
+			}
+		})
+	}
+}
+
+func TestPrintNBytes(t *testing.T) {
+	var out bytes.Buffer
+	p := NewWith(tty.NewWith(os.Stdin, &out, os.Stderr))
+	p.SetEscapes(true)
+
+	b := []byte("ab👩‍👩‍👧cd")
+	n, err := p.Bold(true).PrintNBytes(4, b)
+	if err != nil {
+		t.Errorf("err: %v", err)
+	}
+	want := "\x1b[1mab👩‍👩‍👧\x1b[0m"
+	if got := out.String(); got != want || n != len("ab👩‍👩‍👧") {
+		t.Errorf("err: want (%q), got (%q, %d bytes)", want, out.String(), n)
+	}
+	if got := string(b); got != "ab👩‍👩‍👧cd" {
+		t.Errorf("err: the text changed: (%q)", got)
+	}
+
+	// Taking each emoji on its own, the family doesn't fit.
+	out.Reset()
+	p.SetZWJPolicy(width.ZWJSplit).PrintNBytes(4, b)
+	if got, want := out.String(), "\x1b[1mab\x1b[0m"; got != want {
+		t.Errorf("err: want (%q), got (%q)", want, got)
+	}
+}
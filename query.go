@@ -0,0 +1,168 @@
+package termy
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mec-nyan/termy/input"
+)
+
+// DefaultQueryTimeout is how long to wait for the terminal to answer a query,
+// when the method doesn't let you choose.
+const DefaultQueryTimeout = time.Second
+
+// ErrNoReply is returned when the terminal doesn't answer a query in time.
+// Some terminals just don't support some queries.
+var ErrNoReply = errors.New("err: no reply from the terminal")
+
+// cprReply is the answer to ESC [ 6 n: ESC [ <row> ; <col> R.
+var cprReply = regexp.MustCompile(`^\x1b\[(\d+);(\d+)R$`)
+
+// Query writes "request" to the terminal and waits up to "timeout" for the
+// reply, which is the first sequence from the input "reply" matches (the whole
+// sequence, i.e. ESC [ 1 ; 1 R for a cursor position). It returns the reply and
+// its submatches, like regexp.FindStringSubmatch.
+//
+// Whatever else arrives meanwhile (i.e. the user typing) is kept: it's delivered
+// by the next ReadEvent, or by Events if it's running. The terminal should be
+// uncooked, otherwise the reply is stuck until the user presses Enter.
+// Don't call ReadEvent while a query is waiting.
+// As with Events, the timeout only works if the input is a file (i.e. a
+// terminal): otherwise we can't stop reading.
+func (d *Display) Query(request string, reply *regexp.Regexp, timeout time.Duration) ([]string, error) {
+	m, err := d.queryAll(request, []*regexp.Regexp{reply}, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return m[0], nil
+}
+
+// CurPos asks the terminal for the cursor position: "x" is the column and
+// "y" the row, starting at 1 (like MoveTo).
+// It returns ErrNoReply if the terminal doesn't answer in DefaultQueryTimeout.
+func (d *Display) CurPos() (x, y int, err error) {
+	defer d.replyMode()()
+
+	m, err := d.Query(_csi+"6n", cprReply, DefaultQueryTimeout)
+	if err != nil {
+		return 0, 0, err
+	}
+	y, _ = strconv.Atoi(m[1])
+	x, _ = strconv.Atoi(m[2])
+
+	return x, y, nil
+}
+
+// -------- Internal -------- //
+
+// queryAll writes "request", which may hold several queries, and waits up to
+// "timeout" for their replies. It returns the submatches for each of the
+// "replies" (nil if there was no reply), as soon as the last one arrives:
+// put a query every terminal answers last, and you don't need to wait for
+// the ones a terminal doesn't support. It fails with ErrNoReply if the last
+// one doesn't arrive.
+func (d *Display) queryAll(request string, replies []*regexp.Regexp, timeout time.Duration) ([][]string, error) {
+	if !d.Escapes() {
+		return nil, errors.New("err: can't query the terminal with escape sequences disabled")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	matches := make([][]string, len(replies))
+	var mu sync.Mutex
+	last := len(replies) - 1
+	for i, reply := range replies {
+		stop := d.input().Intercept(reply.Match, func(seq []byte) {
+			mu.Lock()
+			matches[i] = reply.FindStringSubmatch(string(seq))
+			mu.Unlock()
+			if i == last {
+				cancel()
+			}
+		})
+		defer stop()
+	}
+
+	d.write(request)
+	d.Flush()
+
+	if d.eventsRunning() {
+		// The event loop reads for us.
+		<-ctx.Done()
+	} else {
+		for ctx.Err() == nil {
+			ev, err := d.input().ReadEventContext(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					return nil, err
+				}
+				break
+			}
+			d.keep(ev)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if matches[last] == nil {
+		return nil, ErrNoReply
+	}
+	return matches, nil
+}
+
+// replyMode gets the terminal ready for a reply, and returns a function that
+// puts it back as it was.
+func (d *Display) replyMode() (restore func()) {
+	echoing, cooked := d.Echoing(), d.Cooked()
+	// We don't want our terminal to print the reply.
+	if echoing {
+		d.NoEcho()
+	}
+	// The reply doesn't come with an Enter. Whatever the user typed is kept.
+	if cooked {
+		d.UnCookIt()
+	}
+
+	return func() {
+		if cooked {
+			d.CookIt()
+		}
+		if echoing {
+			d.Echo()
+		}
+	}
+}
+
+// eventsRunning reports whether an Events loop is reading the input.
+func (d *Display) eventsRunning() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.loop != nil
+}
+
+// keep saves ev for the next ReadEvent (or Events).
+func (d *Display) keep(ev input.Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.pending = append(d.pending, ev)
+}
+
+// nextPending returns the oldest event kept for later, if any.
+func (d *Display) nextPending() (input.Event, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.pending) == 0 {
+		return nil, false
+	}
+	ev := d.pending[0]
+	d.pending = d.pending[1:]
+	return ev, true
+}
@@ -0,0 +1,74 @@
+package termy
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/mec-nyan/termy/input"
+)
+
+func TestCurPos(t *testing.T) {
+	var out bytes.Buffer
+	// The user is typing while we ask.
+	d, err := NewDisplayWith(
+		WithInput(strings.NewReader("ab\x1b[5;10R\x1b[1;2Rc")),
+		WithOutput(&out),
+		WithoutTermios(),
+		WithEscapes(true),
+	)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	x, y, err := d.CurPos()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if x != 10 || y != 5 {
+		t.Errorf("err: want (10, 5), got (%d, %d)", x, y)
+	}
+	if got, want := out.String(), "\x1b[6n"; got != want {
+		t.Errorf("err: want (%q), got (%q)", want, got)
+	}
+
+	// Nothing else is lost, and the next one looking like a reply is a key again.
+	want := []input.Event{
+		input.Key{Code: input.KeyRune, Rune: 'a'},
+		input.Key{Code: input.KeyRune, Rune: 'b'},
+		input.Key{Code: input.KeyF3, Mods: input.ModShift},
+		input.Key{Code: input.KeyRune, Rune: 'c'},
+	}
+	for _, w := range want {
+		got, err := d.ReadEvent()
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if got != w {
+			t.Errorf("err: want (%v), got (%v)", w, got)
+		}
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	var out bytes.Buffer
+	d, err := NewDisplayWith(WithInput(strings.NewReader("x")), WithOutput(&out), WithoutTermios(), WithEscapes(true))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// The input ends before the reply.
+	if _, err := d.Query("\x1b[c", regexp.MustCompile(`^\x1b\[\?.*c$`), DefaultQueryTimeout); err != io.EOF {
+		t.Errorf("err: want (EOF), got (%v)", err)
+	}
+	if got, _ := d.ReadEvent(); got != (input.Key{Code: input.KeyRune, Rune: 'x'}) {
+		t.Errorf("err: want (x), got (%v)", got)
+	}
+
+	d.SetEscapes(false)
+	if _, _, err := d.CurPos(); err == nil {
+		t.Error("err: want an error with escapes disabled, got nil")
+	}
+}
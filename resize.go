@@ -0,0 +1,72 @@
+package termy
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Size holds the dimensions of the terminal, in characters.
+type Size struct {
+	Rows, Cols int
+}
+
+// NotifyResize lets you know whenever the terminal is resized, instead of having to poll Size.
+// The new size is sent on the returned channel every time a SIGWINCH arrives.
+// Bursts of signals (i.e. while the user drags the window's border) are collapsed: the size is
+// only read once no new signal has arrived for "delay", so you get just the final one.
+// If you're slow to receive, older sizes are dropped in favour of the latest.
+//
+// Call stop when you're done: it unsubscribes from the signal and closes the channel.
+// It's safe to call it more than once.
+func (d *Display) NotifyResize(delay time.Duration) (sizes <-chan Size, stop func()) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, unix.SIGWINCH)
+
+	out := make(chan Size, 1)
+	done := make(chan struct{})
+
+	// Don't report the size we already have.
+	var last Size
+	last.Rows, last.Cols, _ = d.Size()
+
+	go func() {
+		defer close(out)
+		defer signal.Stop(sigs)
+
+		timer := time.NewTimer(delay)
+		timer.Stop()
+
+		for {
+			select {
+			case <-done:
+				timer.Stop()
+				return
+			case <-sigs:
+				// Wait for things to settle down.
+				timer.Reset(delay)
+			case <-timer.C:
+				rows, cols, err := d.Size()
+				if err != nil {
+					continue
+				}
+				size := Size{Rows: rows, Cols: cols}
+				if size == last {
+					continue
+				}
+				last = size
+				// Replace any size the receiver didn't get to see.
+				select {
+				case <-out:
+				default:
+				}
+				out <- size
+			}
+		}
+	}()
+
+	return out, sync.OnceFunc(func() { close(done) })
+}
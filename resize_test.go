@@ -0,0 +1,64 @@
+//go:build linux
+
+package termy
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mec-nyan/termy/internal/pty"
+	"github.com/mec-nyan/termy/term"
+	"golang.org/x/sys/unix"
+)
+
+func TestNotifyResize(t *testing.T) {
+	master, slave, err := pty.Open()
+	if err != nil {
+		t.Skipf("can't open a pty: %v", err)
+	}
+	defer master.Close()
+	defer slave.Close()
+
+	pty.SetSize(master, 24, 80)
+	d := &Display{Settings: *term.New(int(slave.Fd()))}
+
+	sizes, stop := d.NotifyResize(50 * time.Millisecond)
+	defer stop()
+
+	// A burst of resizes, like the ones you get while dragging a window.
+	for i := 0; i < 5; i++ {
+		pty.SetSize(master, 30+i, 100+i)
+		unix.Kill(os.Getpid(), unix.SIGWINCH)
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	want := Size{Rows: 34, Cols: 104}
+	select {
+	case got := <-sizes:
+		if got != want {
+			t.Errorf("err: want (%+v), got (%+v)", want, got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("err: no resize notification")
+	}
+
+	// Only the final size is reported.
+	select {
+	case got := <-sizes:
+		t.Errorf("err: unexpected notification (%+v)", got)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	stop()
+	select {
+	case _, ok := <-sizes:
+		if ok {
+			t.Error("err: channel still open after stop")
+		}
+	case <-time.After(time.Second):
+		t.Error("err: channel not closed after stop")
+	}
+	// Stopping twice is fine.
+	stop()
+}
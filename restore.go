@@ -0,0 +1,156 @@
+package termy
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+// mode is something we changed in the terminal that has to be undone before we leave.
+type mode struct {
+	flag uint
+	// undo takes the terminal out of the mode, redo puts it back.
+	// Neither of them change our records, that's up to the caller.
+	undo, redo func()
+}
+
+// Restore undoes every change made to the terminal, in reverse order:
+// alternate buffer and character set, cursor visibility, text attributes, etc.
+// Finally, it restores the terminal settings (see term.Settings.Restore), unless
+// the Display was created WithoutTermios.
+// It's safe to call it more than once.
+func (d *Display) Restore() error {
+	d.mu.Lock()
+	modes := d.modes
+	d.modes = nil
+	d.flags = 0
+	d.syncDepth = 0
+	d.mu.Unlock()
+
+	for i := len(modes) - 1; i >= 0; i-- {
+		modes[i].undo()
+	}
+
+	// Undoing doesn't change our records, so forget about anything we pushed.
+	d.mu.Lock()
+	d.kitty = nil
+	d.mu.Unlock()
+
+	d.Flush()
+
+	if d.noTermios {
+		return nil
+	}
+	return d.Settings.Restore()
+}
+
+// RestoreOnSignal restores the terminal when the program gets a fatal signal
+// (SIGHUP, SIGINT, SIGQUIT or SIGTERM). The signal is raised again afterwards,
+// so the program still dies the way it would have.
+// Call stop to remove the handler.
+func (d *Display) RestoreOnSignal() (stop func()) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, unix.SIGHUP, unix.SIGINT, unix.SIGQUIT, unix.SIGTERM)
+
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-done:
+			return
+		case sig := <-sigs:
+			d.Restore()
+			// Let the default action take place.
+			signal.Reset(sig)
+			unix.Kill(unix.Getpid(), sig.(unix.Signal))
+		}
+	}()
+
+	return sync.OnceFunc(func() {
+		signal.Stop(sigs)
+		close(done)
+	})
+}
+
+// Guard runs fn (typically your whole program) making sure the terminal is
+// restored however it ends: returning, panicking or killed by a fatal signal.
+// Panics are raised again once the terminal is usable, so you can read them.
+func (d *Display) Guard(fn func() error) error {
+	stop := d.RestoreOnSignal()
+	defer stop()
+
+	defer func() {
+		if r := recover(); r != nil {
+			d.Restore()
+			panic(r)
+		}
+	}()
+
+	err := fn()
+	d.Restore()
+
+	return err
+}
+
+// Exit restores the terminal and then exits the program with the given code.
+// Use it instead of os.Exit, which doesn't run deferred functions.
+func (d *Display) Exit(code int) {
+	d.Restore()
+	os.Exit(code)
+}
+
+// -------- Internal -------- //
+
+// enter records that the terminal is in the mode "flag", which is undone by
+// calling undo and can be set up again calling redo.
+// Entering a mode twice has no effect.
+func (d *Display) enter(flag uint, undo, redo func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.flags&flag == flag {
+		return
+	}
+	d.flags |= flag
+	d.modes = append(d.modes, mode{flag: flag, undo: undo, redo: redo})
+}
+
+// setMode writes the sequence "on" and records the mode "flag", which is undone writing "off".
+func (d *Display) setMode(flag uint, on, off string) {
+	d.write(on)
+	d.enter(flag,
+		func() { d.write(off) },
+		func() { d.write(on) },
+	)
+}
+
+// resetMode writes the sequence "off" and forgets about the mode "flag".
+func (d *Display) resetMode(flag uint, off string) {
+	d.write(off)
+	d.leave(flag)
+}
+
+// leave records that the terminal is no longer in the mode "flag".
+func (d *Display) leave(flag uint) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.flags &^= flag
+	for i, m := range d.modes {
+		if m.flag == flag {
+			d.modes = append(d.modes[:i], d.modes[i+1:]...)
+			break
+		}
+	}
+}
+
+// is checks the state saved by our application for the mode "flag".
+// NOTE: It will NOT check your emulator state directly.
+func (d *Display) is(flag uint) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.flags&flag == flag
+}
@@ -0,0 +1,137 @@
+//go:build linux
+
+package termy
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/mec-nyan/termy/internal/pty"
+	"github.com/mec-nyan/termy/printer"
+)
+
+// newTestDisplay returns a Display whose settings belong to a new pty,
+// and whose output goes to the returned buffer.
+func newTestDisplay(t *testing.T) (*Display, *bytes.Buffer) {
+	t.Helper()
+
+	master, slave, err := pty.Open()
+	if err != nil {
+		t.Skipf("can't open a pty: %v", err)
+	}
+	t.Cleanup(func() {
+		master.Close()
+		slave.Close()
+	})
+
+	var out bytes.Buffer
+	d, err := NewDisplayWith(WithOutput(&out), WithFd(int(slave.Fd())), WithEscapes(true))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	return d, &out
+}
+
+func TestRestore(t *testing.T) {
+	d, out := newTestDisplay(t)
+
+	d.UnCookIt()
+	d.NoEcho()
+	d.HideCur()
+	d.EnterAltBuf()
+	d.EnterACS()
+	d.Bold(true).Send()
+
+	if err := d.Restore(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	got := out.String()
+	// Everything is undone in reverse order.
+	want := "\x1b[0m" + "\x1b(B" + "\x1b[?1049l" + "\x1b[?25h"
+	if !strings.HasSuffix(got, want) {
+		t.Errorf("err: want suffix (%q), got (%q)", want, got)
+	}
+
+	if d.flags != 0 || len(d.modes) != 0 {
+		t.Errorf("err: modes left behind: flags (%b), modes (%d)", d.flags, len(d.modes))
+	}
+	if !d.Cooked() || !d.Echoing() {
+		t.Error("err: terminal settings not restored")
+	}
+}
+
+func TestRestoreExitedModes(t *testing.T) {
+	d, out := newTestDisplay(t)
+
+	d.EnterAltBuf()
+	d.HideCur()
+	d.ExitAltBuf()
+
+	d.Restore()
+	// Calling it again doesn't do anything.
+	d.Restore()
+
+	got := out.String()
+	want := "\x1b[?1049h" + "\x1b[?25l" + "\x1b[?1049l" + "\x1b[?25h"
+	if got != want {
+		t.Errorf("err: want (%q), got (%q)", want, got)
+	}
+}
+
+func TestGuardPanic(t *testing.T) {
+	d, out := newTestDisplay(t)
+
+	func() {
+		defer func() {
+			if p := recover(); p != "boom" {
+				t.Errorf("err: want the panic to go on, got (%v)", p)
+			}
+		}()
+		d.Guard(func() error {
+			d.UnCookIt()
+			d.EnterAltBuf()
+			panic("boom")
+		})
+	}()
+
+	if got := out.String(); !strings.HasSuffix(got, "\x1b[?1049l") {
+		t.Errorf("err: alt buffer not restored: (%q)", got)
+	}
+	if !d.Cooked() {
+		t.Error("err: terminal settings not restored")
+	}
+}
+
+// TestRestoreOnSignal runs itself in a child process that gets killed by SIGTERM.
+func TestRestoreOnSignal(t *testing.T) {
+	if os.Getenv("TERMY_TEST_SIGNAL") == "1" {
+		d := &Display{Printer: printer.New(), noTermios: true}
+		d.SetEscapes(true)
+		d.RestoreOnSignal()
+		d.HideCur()
+		syscall.Kill(os.Getpid(), syscall.SIGTERM)
+		select {}
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestRestoreOnSignal$")
+	cmd.Env = append(os.Environ(), "TERMY_TEST_SIGNAL=1")
+	out, err := cmd.Output()
+
+	exit, ok := err.(*exec.ExitError)
+	if !ok {
+		t.Fatalf("err: want the child to be killed, got (%v)", err)
+	}
+	status := exit.Sys().(syscall.WaitStatus)
+	if !status.Signaled() || status.Signal() != syscall.SIGTERM {
+		t.Errorf("err: want the child killed by SIGTERM, got (%v)", status)
+	}
+	if want := "\x1b[?25l\x1b[?25h"; string(out) != want {
+		t.Errorf("err: want (%q), got (%q)", want, out)
+	}
+}
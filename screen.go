@@ -0,0 +1,358 @@
+package termy
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/mec-nyan/termy/byteme"
+	"github.com/mec-nyan/termy/colour"
+	"github.com/mec-nyan/termy/style"
+)
+
+// Cell is a single character on the Screen, with its colours and style.
+type Cell struct {
+	// Text is what's drawn in the cell: usually a single rune, but it can be
+	// a character made of several (i.e. "é"). An empty cell is a space.
+	// Wide characters (see the width package) take this cell and the next.
+	Text   string
+	Colour colour.Colour
+	Style  style.Style
+}
+
+// cell is a Cell as we keep it: with its attributes in a single SGR
+// sequence, so we can compare them.
+type cell struct {
+	text string
+	sgr  string
+	// wide is set on a wide character, and cont on the cell after it, which
+	// the character covers.
+	wide, cont bool
+}
+
+// Screen is a grid of cells you draw into, and then Show on the terminal.
+// It keeps what's on the terminal (the front buffer) and what you're drawing
+// (the back buffer), so Show only sends what changed: no flicker, and a lot
+// fewer bytes than redrawing everything.
+// Like MoveTo, the coordinates start at 1 from the top left corner.
+//
+// The Screen assumes it owns the terminal: if something else draws on it,
+// call Invalidate so everything is drawn again on the next Show.
+type Screen struct {
+	d          *Display
+	rows, cols int
+	// front is what's on the terminal, back what we're drawing.
+	front, back []cell
+	// valid is false when we don't know what's on the terminal, i.e. before
+	// the first Show and after a resize.
+	valid bool
+	// curX and curY are where the cursor goes after Show (0 to leave it be).
+	curX, curY int
+}
+
+// NewScreen creates a Screen as big as the terminal, see Display.Size.
+// You'll probably want to EnterAltBuf first.
+func (d *Display) NewScreen() (*Screen, error) {
+	rows, cols, err := d.Size()
+	if err != nil {
+		return nil, err
+	}
+	return d.NewScreenSize(rows, cols)
+}
+
+// NewScreenSize creates a Screen with the given size, instead of the terminal's.
+func (d *Display) NewScreenSize(rows, cols int) (*Screen, error) {
+	if rows < 0 || cols < 0 {
+		return nil, errors.New("err: the screen size can't be negative")
+	}
+	s := &Screen{d: d}
+	s.Resize(rows, cols)
+	return s, nil
+}
+
+// Size returns the size of the Screen, in cells.
+func (s *Screen) Size() (rows, cols int) {
+	return s.rows, s.cols
+}
+
+// Resize changes the size of the Screen. What you've drawn is kept, as long
+// as it fits. Everything is drawn again on the next Show, as the terminal
+// shuffles its content on its own when resized.
+func (s *Screen) Resize(rows, cols int) {
+	rows, cols = max(rows, 0), max(cols, 0)
+
+	back := make([]cell, rows*cols)
+	for y := range min(rows, s.rows) {
+		copy(back[y*cols:y*cols+min(cols, s.cols)], s.back[y*s.cols:])
+		if last := y*cols + cols - 1; cols < s.cols && back[last].wide {
+			// Half of it is gone.
+			back[last] = cell{}
+		}
+	}
+
+	s.rows, s.cols = rows, cols
+	s.back = back
+	s.front = make([]cell, rows*cols)
+	s.valid = false
+}
+
+// Fit resizes the Screen to the terminal's size, if it's changed.
+// Call it when you get an input.Resize (see Display.Events), or use
+// Resize with the new size.
+func (s *Screen) Fit() error {
+	rows, cols, err := s.d.Size()
+	if err != nil {
+		return err
+	}
+	if rows != s.rows || cols != s.cols {
+		s.Resize(rows, cols)
+	}
+	return nil
+}
+
+// Set puts c at (x, y). Anything outside the Screen is ignored, and so is a
+// wide character in the last column, as it doesn't fit.
+// Text wider than two cells (i.e. a ZWJ sequence with width.ZWJSplit) is
+// ignored too: it's not one character on the terminal, use PrintAt instead.
+func (s *Screen) Set(x, y int, c Cell) {
+	w := s.d.Measure().String(c.Text)
+	if w > 2 {
+		return
+	}
+	s.put(x, y, cell{text: c.Text, sgr: s.cellSGR(c.Colour, c.Style), wide: w > 1})
+}
+
+// Text returns the text at (x, y), as it will be shown.
+// The cell covered by a wide character is empty.
+func (s *Screen) Text(x, y int) string {
+	if i, ok := s.index(x, y); ok {
+		return s.back[i].text
+	}
+	return ""
+}
+
+// PrintAt puts the string "str" at (x, y), one character (grapheme cluster)
+// per cell, two for the wide ones, with the given colours and style.
+// Characters with no width of their own (i.e. control characters) are
+// skipped. It stops at the right edge, and returns how many cells it took.
+func (s *Screen) PrintAt(x, y int, str string, c colour.Colour, st style.Style) int {
+	sgr := s.cellSGR(c, st)
+	m := s.d.Measure()
+	n := 0
+	for str != "" {
+		size, w := m.Next(byteme.UnsafeStrToBytes(str))
+		text := str[:size]
+		str = str[size:]
+		if w == 0 {
+			continue
+		}
+		// With width.ZWJSplit, the emoji in a ZWJ sequence take their own cells.
+		parts := []string{text}
+		if w > 2 {
+			parts = strings.SplitAfter(text, "\u200d")
+		}
+		for _, part := range parts {
+			w := m.String(part)
+			if !s.put(x+n, y, cell{text: part, sgr: sgr, wide: w > 1}) {
+				return n
+			}
+			n += w
+		}
+	}
+	return n
+}
+
+// Clear empties the Screen, so you can draw the next frame from scratch.
+func (s *Screen) Clear() {
+	clear(s.back)
+}
+
+// SetCursor moves the cursor to (x, y) after Show, i.e. to where the user is
+// typing. Use (0, 0) to leave it wherever drawing ends.
+func (s *Screen) SetCursor(x, y int) {
+	s.curX, s.curY = x, y
+}
+
+// Invalidate makes the next Show draw everything, not just the changes.
+func (s *Screen) Invalidate() {
+	s.valid = false
+}
+
+// Show draws what changed since the last Show on the terminal.
+// It only sends the cursor moves, attribute changes and text needed for the
+// cells that changed, all in a single write. For big redraws, run it in
+// Display.Sync so the terminal doesn't show half a frame.
+func (s *Screen) Show() error {
+	if !s.d.Escapes() {
+		return errors.New("err: can't show the screen with escape sequences disabled")
+	}
+
+	var out strings.Builder
+	// We don't know where the cursor is, but the last Show left the
+	// default attributes.
+	x, y := -1, -1
+	sgr := "0"
+
+	if !s.valid {
+		// Start from a blank terminal, so we can skip the blank cells.
+		out.WriteString(_csi + "0m" + _csi + "H" + _csi + "2J")
+		x, y = 1, 1
+		clear(s.front)
+	}
+
+	for row := range s.rows {
+		for col := range s.cols {
+			i := row*s.cols + col
+			if s.back[i] == s.front[i] || s.back[i].cont {
+				// The covered cells are drawn with their wide character.
+				continue
+			}
+			c := s.back[i]
+
+			out.WriteString(moveCursor(x, y, col+1, row+1))
+			if want := cellSGR0(c.sgr); want != sgr {
+				out.WriteString(_csi + want + "m")
+				sgr = want
+			}
+			if c.text == "" {
+				out.WriteByte(' ')
+			} else {
+				out.WriteString(c.text)
+			}
+
+			x, y = col+2, row+1
+			if c.wide {
+				x++
+			}
+			if x > s.cols {
+				// The cursor is stuck in the last column, waiting to wrap.
+				x, y = -1, -1
+			}
+		}
+	}
+
+	if sgr != "0" {
+		out.WriteString(_csi + "0m")
+	}
+	if s.curX > 0 && s.curY > 0 {
+		out.WriteString(moveCursor(x, y, s.curX, s.curY))
+	}
+
+	copy(s.front, s.back)
+	s.valid = true
+
+	if out.Len() == 0 {
+		return nil
+	}
+	_, err := s.d.Write(byteme.UnsafeStrToBytes(out.String()))
+	return err
+}
+
+// -------- Internal -------- //
+
+// index returns the position of (x, y) in the buffers, if it's on the Screen.
+func (s *Screen) index(x, y int) (int, bool) {
+	if x < 1 || y < 1 || x > s.cols || y > s.rows {
+		return 0, false
+	}
+	return (y-1)*s.cols + x - 1, true
+}
+
+// put puts c at (x, y), if it fits, keeping the wide characters whole: the
+// ones it overwrites any part of are blanked.
+func (s *Screen) put(x, y int, c cell) bool {
+	i, ok := s.index(x, y)
+	if !ok || c.wide && x == s.cols {
+		return false
+	}
+	s.unwide(i)
+	s.back[i] = c
+	if c.wide {
+		s.unwide(i + 1)
+		s.back[i+1] = cell{sgr: c.sgr, cont: true}
+	}
+	return true
+}
+
+// unwide blanks the wide character at "i", if any part of it is there.
+func (s *Screen) unwide(i int) {
+	switch {
+	case s.back[i].cont:
+		s.back[i-1] = cell{}
+		s.back[i] = cell{}
+	case s.back[i].wide:
+		s.back[i] = cell{}
+		s.back[i+1] = cell{}
+	}
+}
+
+// cellSGR returns the attributes for the colour c and style st, from the
+// default ones, with the colours approximated to the Display's profile.
+// An empty string means the default ones, so the zero cell is a blank one.
+func (s *Screen) cellSGR(c colour.Colour, st style.Style) string {
+	c = c.Convert(s.d.Colour.Profile())
+	code := c.Code()
+	if st := st.Code(); st != "0" {
+		code = joinSGR(st, code)
+	}
+	return code
+}
+
+// cellSGR0 returns the full sequence for the attributes "sgr": the reset
+// goes first, so nothing is left over from the previous cell.
+func cellSGR0(sgr string) string {
+	return joinSGR("0", sgr)
+}
+
+// joinSGR joins two parts of an SGR sequence, either of which may be empty.
+func joinSGR(a, b string) string {
+	if a == "" {
+		return b
+	}
+	if b == "" {
+		return a
+	}
+	return a + ";" + b
+}
+
+// moveCursor returns the shortest sequence that moves the cursor from
+// (fromX, fromY) to (toX, toY). A negative "from" means we don't know where
+// the cursor is.
+func moveCursor(fromX, fromY, toX, toY int) string {
+	if fromX == toX && fromY == toY {
+		return ""
+	}
+	// Absolute, which always works.
+	best := _csi + strconv.Itoa(toY) + ";" + strconv.Itoa(toX) + "H"
+	if fromX < 0 || fromY < 0 {
+		return best
+	}
+
+	candidates := []string{}
+	switch {
+	case fromY == toY && toX > fromX:
+		candidates = append(candidates, _csi+count(toX-fromX)+"C")
+	case fromY == toY && toX < fromX:
+		candidates = append(candidates, _csi+count(fromX-toX)+"D")
+	case fromY+1 == toY && toX == 1:
+		candidates = append(candidates, "\r\n")
+	}
+	if fromY == toY && toX == 1 {
+		candidates = append(candidates, "\r")
+	}
+
+	for _, c := range candidates {
+		if len(c) < len(best) {
+			best = c
+		}
+	}
+	return best
+}
+
+// count returns the parameter for a relative move of n cells: 1 is the default.
+func count(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
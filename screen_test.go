@@ -0,0 +1,242 @@
+package termy
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mec-nyan/termy/colour"
+	"github.com/mec-nyan/termy/style"
+	"github.com/mec-nyan/termy/width"
+)
+
+// newTestScreen returns a Screen that writes to a buffer.
+func newTestScreen(t *testing.T, rows, cols int) (*Screen, *bytes.Buffer) {
+	t.Helper()
+
+	var out bytes.Buffer
+	d, err := NewDisplayWith(WithOutput(&out), WithoutTermios(), WithEscapes(true))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	s, err := d.NewScreenSize(rows, cols)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return s, &out
+}
+
+// show calls Show and returns what it wrote.
+func show(t *testing.T, s *Screen, out *bytes.Buffer) string {
+	t.Helper()
+
+	out.Reset()
+	if err := s.Show(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return out.String()
+}
+
+func TestScreenShow(t *testing.T) {
+	s, out := newTestScreen(t, 3, 10)
+
+	var red colour.Colour
+	red.SetFg(colour.Red)
+	var bold style.Style
+	bold.Bold()
+
+	// The first time everything is drawn, on a blank terminal.
+	s.PrintAt(1, 1, "hi", colour.Colour{}, style.Style{})
+	s.PrintAt(4, 2, "ok", red, bold)
+	want := "\x1b[0m\x1b[H\x1b[2J" + "hi" + "\x1b[2;4H" + "\x1b[0;1;" + red.Code() + "mok" + "\x1b[0m"
+	if got := show(t, s, out); got != want {
+		t.Errorf("err: want (%q), got (%q)", want, got)
+	}
+
+	// Nothing changed, nothing to send.
+	if got := show(t, s, out); got != "" {
+		t.Errorf("err: want nothing, got (%q)", got)
+	}
+
+	// Only the changes.
+	s.PrintAt(2, 1, "o", colour.Colour{}, style.Style{})
+	s.PrintAt(8, 1, "x", colour.Colour{}, style.Style{})
+	s.Set(4, 2, Cell{})
+	want = "\x1b[1;2Ho" + "\x1b[5Cx" + "\x1b[2;4H "
+	if got := show(t, s, out); got != want {
+		t.Errorf("err: want (%q), got (%q)", want, got)
+	}
+
+	// Same attributes, same sequence: not sent again.
+	s.Clear()
+	s.PrintAt(1, 2, "ab", red, style.Style{})
+	s.PrintAt(1, 3, "c", red, style.Style{})
+	want = "\x1b[1;1H  " + "\x1b[5C " + "\r\n" + "\x1b[0;" + red.Code() + "mab" + "\x1b[2C\x1b[0m " + "\r\n" + "\x1b[0;" + red.Code() + "mc" + "\x1b[0m"
+	if got := show(t, s, out); got != want {
+		t.Errorf("err: want (%q), got (%q)", want, got)
+	}
+}
+
+func TestScreenCursor(t *testing.T) {
+	s, out := newTestScreen(t, 2, 3)
+
+	s.PrintAt(1, 1, "abc", colour.Colour{}, style.Style{})
+	s.SetCursor(2, 2)
+	// After the last column we don't know where the cursor is.
+	want := "\x1b[0m\x1b[H\x1b[2J" + "abc" + "\x1b[2;2H"
+	if got := show(t, s, out); got != want {
+		t.Errorf("err: want (%q), got (%q)", want, got)
+	}
+}
+
+func TestScreenResize(t *testing.T) {
+	s, out := newTestScreen(t, 2, 4)
+
+	s.PrintAt(1, 1, "abcd", colour.Colour{}, style.Style{})
+	s.PrintAt(1, 2, "efgh", colour.Colour{}, style.Style{})
+	show(t, s, out)
+
+	s.Resize(3, 2)
+	if rows, cols := s.Size(); rows != 3 || cols != 2 {
+		t.Errorf("err: want (3, 2), got (%d, %d)", rows, cols)
+	}
+	for _, c := range []struct {
+		x, y int
+		want string
+	}{{1, 1, "a"}, {2, 1, "b"}, {1, 2, "e"}, {2, 2, "f"}, {1, 3, ""}, {3, 1, ""}} {
+		if got := s.Text(c.x, c.y); got != c.want {
+			t.Errorf("err: (%d, %d): want (%q), got (%q)", c.x, c.y, c.want, got)
+		}
+	}
+
+	// Everything is drawn again.
+	want := "\x1b[0m\x1b[H\x1b[2J" + "ab" + "\x1b[2;1H" + "ef"
+	if got := show(t, s, out); got != want {
+		t.Errorf("err: want (%q), got (%q)", want, got)
+	}
+}
+
+func TestMoveCursor(t *testing.T) {
+	// Given
+	cases := []struct {
+		fromX, fromY, toX, toY int
+		want                   string
+	}{
+		{fromX: 3, fromY: 3, toX: 3, toY: 3, want: ""},
+		{fromX: -1, fromY: -1, toX: 3, toY: 3, want: "\x1b[3;3H"},
+		{fromX: 3, fromY: 3, toX: 4, toY: 3, want: "\x1b[C"},
+		{fromX: 3, fromY: 3, toX: 20, toY: 3, want: "\x1b[17C"},
+		{fromX: 30, fromY: 3, toX: 28, toY: 3, want: "\x1b[2D"},
+		{fromX: 30, fromY: 3, toX: 1, toY: 3, want: "\r"},
+		{fromX: 30, fromY: 3, toX: 1, toY: 4, want: "\r\n"},
+		{fromX: 30, fromY: 3, toX: 2, toY: 5, want: "\x1b[5;2H"},
+	}
+
+	for _, c := range cases {
+		if got := moveCursor(c.fromX, c.fromY, c.toX, c.toY); got != c.want {
+			t.Errorf("err: (%d, %d) to (%d, %d): want (%q), got (%q)", c.fromX, c.fromY, c.toX, c.toY, c.want, got)
+		}
+	}
+}
+
+func TestScreenWide(t *testing.T) {
+	s, out := newTestScreen(t, 2, 5)
+
+	if n := s.PrintAt(1, 1, "a日b", colour.Colour{}, style.Style{}); n != 4 {
+		t.Errorf("err: want (4) cells, got (%d)", n)
+	}
+	// It doesn't fit in the last column.
+	if n := s.PrintAt(4, 2, "xé日", colour.Colour{}, style.Style{}); n != 2 {
+		t.Errorf("err: want (2) cells, got (%d)", n)
+	}
+	want := "\x1b[0m\x1b[H\x1b[2J" + "a日b" + "\x1b[2;4H" + "xé"
+	if got := show(t, s, out); got != want {
+		t.Errorf("err: want (%q), got (%q)", want, got)
+	}
+
+	// Overwriting half of it blanks the other half.
+	s.PrintAt(3, 1, "c", colour.Colour{}, style.Style{})
+	if got := s.Text(2, 1); got != "" {
+		t.Errorf("err: want the wide character gone, got (%q)", got)
+	}
+	want = "\x1b[1;2H c"
+	if got := show(t, s, out); got != want {
+		t.Errorf("err: want (%q), got (%q)", want, got)
+	}
+
+	// Wide at the end of the row: we don't know where the cursor is after it.
+	s.PrintAt(4, 1, "日", colour.Colour{}, style.Style{})
+	s.PrintAt(1, 2, "z", colour.Colour{}, style.Style{})
+	want = "\x1b[1;4H日" + "\x1b[2;1Hz"
+	if got := show(t, s, out); got != want {
+		t.Errorf("err: want (%q), got (%q)", want, got)
+	}
+
+	// Resizing through a wide character drops it.
+	s.Resize(2, 4)
+	if got := s.Text(4, 1); got != "" {
+		t.Errorf("err: want the wide character gone, got (%q)", got)
+	}
+}
+
+func TestScreenClusters(t *testing.T) {
+	s, out := newTestScreen(t, 1, 8)
+
+	// A flag, an accent and a family: one character each.
+	if n := s.PrintAt(1, 1, "🇪🇸é👩‍👩‍👧!", colour.Colour{}, style.Style{}); n != 6 {
+		t.Errorf("err: want (6) cells, got (%d)", n)
+	}
+	for x, want := range []string{"🇪🇸", "", "é", "👩‍👩‍👧", "", "!"} {
+		if got := s.Text(x+1, 1); got != want {
+			t.Errorf("err: (%d, 1): want (%q), got (%q)", x+1, want, got)
+		}
+	}
+	want := "\x1b[0m\x1b[H\x1b[2J" + "🇪🇸é👩‍👩‍👧!"
+	if got := show(t, s, out); got != want {
+		t.Errorf("err: want (%q), got (%q)", want, got)
+	}
+
+	// Taking each emoji on its own, the family takes six cells.
+	s.d.SetZWJPolicy(width.ZWJSplit)
+	s.Clear()
+	if n := s.PrintAt(1, 1, "👩‍👩‍👧!", colour.Colour{}, style.Style{}); n != 7 {
+		t.Errorf("err: want (7) cells, got (%d)", n)
+	}
+	for x, want := range []string{"👩‍", "", "👩‍", "", "👧", "", "!"} {
+		if got := s.Text(x+1, 1); got != want {
+			t.Errorf("err: (%d, 1): want (%q), got (%q)", x+1, want, got)
+		}
+	}
+
+	// A single cell can't hold them, so Set leaves it alone.
+	s.Clear()
+	s.Set(1, 1, Cell{Text: "👩‍👩‍👧"})
+	s.Set(2, 1, Cell{Text: "👩"})
+	for x, want := range []string{"", "👩", ""} {
+		if got := s.Text(x+1, 1); got != want {
+			t.Errorf("err: (%d, 1): want (%q), got (%q)", x+1, want, got)
+		}
+	}
+}
+
+func TestScreenColourProfile(t *testing.T) {
+	var out bytes.Buffer
+	d, err := NewDisplayWith(WithOutput(&out), WithoutTermios(), WithEscapes(true), WithColourProfile(colour.ANSI16))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	s, err := d.NewScreenSize(1, 4)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	var orange colour.Colour
+	orange.SetFgRGB(255, 128, 0).SetBg(196)
+	s.PrintAt(1, 1, "ab", orange, style.Style{})
+	s.Set(3, 1, Cell{Text: "c", Colour: orange})
+
+	// Only the 16 basic colours reach the terminal.
+	want := "\x1b[0m\x1b[H\x1b[2J" + "\x1b[0;33;101mabc" + "\x1b[0m"
+	if got := show(t, s, &out); got != want {
+		t.Errorf("err: want (%q), got (%q)", want, got)
+	}
+}
@@ -0,0 +1,110 @@
+package termy
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+// HandleSuspend makes job control (Ctrl-Z and `fg`) play nice with your program.
+// On SIGTSTP the terminal is restored (settings, alternate buffer, cursor, etc.)
+// and the program is stopped. When it's continued (SIGCONT), every change is
+// applied again and redraw is called, so you can paint the screen again.
+// redraw can be nil.
+// Call stop to remove the handler.
+func (d *Display) HandleSuspend(redraw func()) (stop func()) {
+	tstp := make(chan os.Signal, 1)
+	cont := make(chan os.Signal, 1)
+	signal.Notify(tstp, unix.SIGTSTP)
+	signal.Notify(cont, unix.SIGCONT)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer signal.Stop(tstp)
+		defer signal.Stop(cont)
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-tstp:
+			}
+
+			d.suspend()
+
+			// Forget about any previous continue.
+			select {
+			case <-cont:
+			default:
+			}
+			// Raise SIGTSTP again with its default action, so we stop the
+			// way the shell expects from Ctrl-Z. signal.Reset alone leaves the
+			// runtime's handler in place (and it drops the signal), so set
+			// the default action ourselves.
+			signal.Reset(unix.SIGTSTP)
+			if err := defaultAction(unix.SIGTSTP); err == nil {
+				unix.Kill(unix.Getpid(), unix.SIGTSTP)
+			} else {
+				unix.Kill(unix.Getpid(), unix.SIGSTOP)
+			}
+
+			// We're stopped here, until someone sends SIGCONT.
+			select {
+			case <-done:
+				return
+			case <-cont:
+			}
+			// The runtime still thinks its handler is there: going through
+			// Ignore gets it installed again.
+			signal.Ignore(unix.SIGTSTP)
+			signal.Notify(tstp, unix.SIGTSTP)
+
+			d.resume()
+			if redraw != nil {
+				redraw()
+			}
+		}
+	}()
+
+	return sync.OnceFunc(func() {
+		signal.Stop(tstp)
+		close(done)
+	})
+}
+
+// -------- Internal -------- //
+
+// suspend undoes every mode in reverse order and restores the terminal settings.
+// Unlike Restore, it keeps our records, so resume can set everything up again.
+func (d *Display) suspend() {
+	d.mu.Lock()
+	modes := append([]mode{}, d.modes...)
+	d.mu.Unlock()
+
+	for i := len(modes) - 1; i >= 0; i-- {
+		modes[i].undo()
+	}
+	d.Flush()
+	if !d.noTermios {
+		d.Settings.Suspend()
+	}
+}
+
+// resume applies the current terminal settings and every mode again.
+func (d *Display) resume() {
+	if !d.noTermios {
+		d.Settings.Resume()
+	}
+
+	d.mu.Lock()
+	modes := append([]mode{}, d.modes...)
+	d.mu.Unlock()
+
+	for _, m := range modes {
+		m.redo()
+	}
+	d.Flush()
+}
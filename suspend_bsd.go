@@ -0,0 +1,20 @@
+//go:build darwin || dragonfly || freebsd || openbsd
+
+package termy
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// defaultAction sets the action for sig back to the default (SIG_DFL).
+// A zeroed sigaction is just that, whatever the layout of the struct.
+func defaultAction(sig unix.Signal) error {
+	var act [4]uint64
+	_, _, errno := unix.RawSyscall(unix.SYS_SIGACTION, uintptr(sig), uintptr(unsafe.Pointer(&act)), 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package termy
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// defaultAction sets the action for sig back to the default (SIG_DFL).
+// A zeroed sigaction is just that, whatever the layout of the struct.
+func defaultAction(sig unix.Signal) error {
+	var act [4]uint64
+	// The kernel wants the size of its own sigset_t, not glibc's.
+	_, _, errno := unix.RawSyscall6(unix.SYS_RT_SIGACTION, uintptr(sig), uintptr(unsafe.Pointer(&act)), 0, 8, 0, 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package termy
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// defaultAction sets the action for sig back to the default (SIG_DFL).
+// A zeroed sigaction is just that, whatever the layout of the struct.
+// The default action needs no trampoline.
+func defaultAction(sig unix.Signal) error {
+	var act [4]uint64
+	_, _, errno := unix.RawSyscall6(unix.SYS___SIGACTION_SIGTRAMP, uintptr(sig), uintptr(unsafe.Pointer(&act)), 0, 0, 0, 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
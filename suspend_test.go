@@ -0,0 +1,84 @@
+//go:build linux
+
+package termy
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/mec-nyan/termy/printer"
+)
+
+// TestHandleSuspend runs itself in a child process that gets stopped and continued.
+func TestHandleSuspend(t *testing.T) {
+	if os.Getenv("TERMY_TEST_SUSPEND") == "1" {
+		d := &Display{Printer: printer.New(), noTermios: true}
+		d.SetEscapes(true)
+		redraws := 0
+		d.HandleSuspend(func() {
+			d.Print("redraw")
+			// Once more, to see the handler is still there.
+			if redraws++; redraws == 1 {
+				syscall.Kill(os.Getpid(), syscall.SIGTSTP)
+				return
+			}
+			os.Exit(0)
+		})
+		d.HideCur()
+		d.EnterAltBuf()
+		syscall.Kill(os.Getpid(), syscall.SIGTSTP)
+		select {}
+	}
+
+	var out bytes.Buffer
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHandleSuspend$")
+	cmd.Env = append(os.Environ(), "TERMY_TEST_SUSPEND=1")
+	cmd.Stdout = &out
+	// In a process group of its own, with us outside it: the kernel drops
+	// SIGTSTP for orphaned groups.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	for range 2 {
+		var status syscall.WaitStatus
+		for deadline := time.Now().Add(5 * time.Second); ; {
+			pid, err := syscall.Wait4(cmd.Process.Pid, &status, syscall.WUNTRACED|syscall.WNOHANG, nil)
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			if pid != 0 {
+				break
+			}
+			if time.Now().After(deadline) {
+				cmd.Process.Kill()
+				cmd.Wait()
+				t.Skip("the child was never stopped, job control may not be available")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		// Stopped by SIGTSTP, like any other program on Ctrl-Z.
+		if !status.Stopped() || status.StopSignal() != syscall.SIGTSTP {
+			t.Fatalf("err: want the child stopped by SIGTSTP, got (%v)", status)
+		}
+		cmd.Process.Signal(syscall.SIGCONT)
+	}
+
+	if err := cmd.Wait(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	want := "\x1b[?25l\x1b[?1049h" + // Set up.
+		"\x1b[?1049l\x1b[?25h" + // Suspend, in reverse order.
+		"\x1b[?25l\x1b[?1049h" + // Resume.
+		"redraw" +
+		"\x1b[?1049l\x1b[?25h" + "\x1b[?25l\x1b[?1049h" + "redraw"
+	if got := out.String(); got != want {
+		t.Errorf("err: want (%q), got (%q)", want, got)
+	}
+}
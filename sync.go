@@ -0,0 +1,85 @@
+package termy
+
+// syncMode is the DEC private mode for synchronized output.
+const syncMode = 2026
+
+// BeginSync starts a synchronized update: the terminal keeps showing the
+// last frame while you draw the next one, and shows it all at once on
+// EndSync, so big redraws don't tear.
+// Updates can be nested: only the outermost one counts, so every BeginSync
+// needs its EndSync.
+// It does nothing on terminals that don't support it (see SyncSupported).
+// If there was no Probe, the first call asks the terminal, and may wait up
+// to DefaultQueryTimeout if it doesn't answer: call Probe when it suits you.
+// A pending update is ended on Restore.
+func (d *Display) BeginSync() {
+	supported := d.SyncSupported()
+
+	d.mu.Lock()
+	d.syncDepth++
+	first := d.syncDepth == 1
+	d.mu.Unlock()
+
+	if first && supported {
+		d.setMode(syncOutput, decset(syncMode), decrst(syncMode))
+	}
+}
+
+// EndSync ends the update started by BeginSync. The outermost one shows what
+// was drawn since, flushing the output (see printer.Printer.Flush).
+func (d *Display) EndSync() {
+	d.mu.Lock()
+	if d.syncDepth == 0 {
+		d.mu.Unlock()
+		return
+	}
+	d.syncDepth--
+	last := d.syncDepth == 0
+	d.mu.Unlock()
+
+	if !last {
+		return
+	}
+	if d.is(syncOutput) {
+		d.resetMode(syncOutput, decrst(syncMode))
+	}
+	d.Flush()
+}
+
+// Sync runs draw in a synchronized update (see BeginSync), which ends
+// however draw does, even panicking.
+//
+//	err := d.Sync(func() error {
+//		return screen.Show()
+//	})
+func (d *Display) Sync(draw func() error) error {
+	d.BeginSync()
+	defer d.EndSync()
+
+	return draw()
+}
+
+// Synced reports whether we're in a synchronized update.
+// NOTE: It will NOT check your emulator state directly.
+func (d *Display) Synced() bool {
+	return d.is(syncOutput)
+}
+
+// SyncSupported reports whether the terminal supports synchronized output,
+// as it told Probe (DECRQM). If there was no Probe, it calls it the first
+// time: once only, even if the terminal doesn't answer.
+func (d *Display) SyncSupported() bool {
+	if !d.Escapes() {
+		return false
+	}
+
+	d.mu.Lock()
+	probed := d.caps.Modes != nil || d.syncProbed
+	d.syncProbed = true
+	d.mu.Unlock()
+
+	if !probed {
+		d.Probe()
+	}
+	return d.Capabilities().Supports(syncMode)
+}
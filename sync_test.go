@@ -0,0 +1,154 @@
+package termy
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mec-nyan/termy/printer"
+)
+
+func TestSync(t *testing.T) {
+	// Given
+	cases := []struct {
+		name    string
+		replies string
+		want    string
+	}{
+		{name: "Supported", replies: "\x1b[?2026;2$y\x1b[?62c", want: "\x1b[?2026h" + "frame" + "\x1b[?2026l"},
+		{name: "Not recognised", replies: "\x1b[?62c", want: "frame"},
+		{name: "Can't be set", replies: "\x1b[?2026;4$y\x1b[?62c", want: "frame"},
+		{name: "No answer", replies: "", want: "frame"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d, out := newReplyDisplay(t, c.replies)
+
+			d.SyncSupported()
+			// Just the frames, not the probe.
+			out.Reset()
+
+			for range 2 {
+				err := d.Sync(func() error {
+					d.Print("frame")
+					return errors.New("oops")
+				})
+				if err == nil || err.Error() != "oops" {
+					t.Errorf("err: want the error from draw, got (%v)", err)
+				}
+				if d.Synced() {
+					t.Error("err: want the update ended")
+				}
+				if got := out.String(); got != c.want {
+					t.Errorf("err: want (%q), got (%q)", c.want, got)
+				}
+				out.Reset()
+			}
+		})
+	}
+}
+
+func TestSyncRestore(t *testing.T) {
+	d, out := newReplyDisplay(t, "")
+	d.caps.Modes = map[int]ModeState{syncMode: ModeReset}
+
+	d.BeginSync()
+	if !d.Synced() {
+		t.Error("err: want a synchronized update")
+	}
+	if err := d.Restore(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if d.Synced() {
+		t.Error("err: want the update ended on Restore")
+	}
+
+	// EndSync has nothing left to do.
+	d.EndSync()
+	if got, want := out.String(), "\x1b[?2026h\x1b[?2026l"; got != want {
+		t.Errorf("err: want (%q), got (%q)", want, got)
+	}
+}
+
+func TestSyncWithoutEscapes(t *testing.T) {
+	d, out := newReplyDisplay(t, "\x1b[?2026;2$y\x1b[?62c")
+	d.SetEscapes(false)
+
+	d.Sync(func() error { return nil })
+	if d.SyncSupported() {
+		t.Error("err: want no synchronized output without escapes")
+	}
+	// Nothing asked, nothing sent.
+	if got := out.String(); got != "" {
+		t.Errorf("err: want nothing, got (%q)", got)
+	}
+}
+
+func TestSyncNested(t *testing.T) {
+	d, out := newReplyDisplay(t, "")
+	d.caps.Modes = map[int]ModeState{syncMode: ModeReset}
+
+	d.Sync(func() error {
+		d.Print("a")
+		return d.Sync(func() error {
+			d.Print("b")
+			return nil
+		})
+	})
+	if d.Synced() {
+		t.Error("err: want the update ended")
+	}
+	// Just the outermost update.
+	if got, want := out.String(), "\x1b[?2026h"+"ab"+"\x1b[?2026l"; got != want {
+		t.Errorf("err: want (%q), got (%q)", want, got)
+	}
+
+	// Unbalanced EndSync does nothing.
+	out.Reset()
+	d.EndSync()
+	if got := out.String(); got != "" {
+		t.Errorf("err: want nothing, got (%q)", got)
+	}
+}
+
+func TestSyncFlush(t *testing.T) {
+	// Given
+	cases := []struct {
+		name  string
+		state ModeState
+		want  string
+	}{
+		{name: "Supported", state: ModeReset, want: "\x1b[?2026h" + "frame" + "\x1b[?2026l"},
+		{name: "Not supported", state: ModeUnknown, want: "frame"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out bytes.Buffer
+			d, err := NewDisplayWith(
+				WithInput(strings.NewReader("")),
+				WithOutput(&out),
+				WithoutTermios(),
+				WithEscapes(true),
+				WithFlushPolicy(printer.FlushManually),
+			)
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			d.caps.Modes = map[int]ModeState{syncMode: c.state}
+
+			d.Sync(func() error {
+				d.Print("frame")
+				if got := out.String(); got != "" {
+					t.Errorf("err: want nothing written yet, got (%q)", got)
+				}
+				return nil
+			})
+			if got := out.String(); got != c.want {
+				t.Errorf("err: want (%q), got (%q)", c.want, got)
+			}
+		})
+	}
+}
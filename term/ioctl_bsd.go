@@ -0,0 +1,16 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package term
+
+import "golang.org/x/sys/unix"
+
+// BSD flavours (macOS included) use the TIOCGETA family of requests instead.
+const (
+	ioctlGetTermios = unix.TIOCGETA
+	// Apply the changes immediately.
+	ioctlSetTermios = unix.TIOCSETA
+	// Wait until all pending output has been transmitted before applying the changes.
+	ioctlSetTermiosDrain = unix.TIOCSETAW
+	// Like ioctlSetTermiosDrain, but also discard any input not read yet.
+	ioctlSetTermiosFlush = unix.TIOCSETAF
+)
@@ -0,0 +1,14 @@
+package term
+
+import "golang.org/x/sys/unix"
+
+// Linux uses the TCGETS family of requests to read and write the termios structure.
+const (
+	ioctlGetTermios = unix.TCGETS
+	// Apply the changes immediately.
+	ioctlSetTermios = unix.TCSETS
+	// Wait until all pending output has been transmitted before applying the changes.
+	ioctlSetTermiosDrain = unix.TCSETSW
+	// Like ioctlSetTermiosDrain, but also discard any input not read yet.
+	ioctlSetTermiosFlush = unix.TCSETSF
+)
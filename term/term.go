@@ -2,14 +2,12 @@ package term
 
 import (
 	"errors"
+	"fmt"
+	"time"
 
 	"golang.org/x/sys/unix"
 )
 
-type msg struct {
-	on bool
-}
-
 type Settings struct {
 	// saved should only be written once and should be kept unchanged, so we can
 	// restore the terminal to its previous settings at the end of our program.
@@ -29,7 +27,7 @@ func New(fd int) *Settings {
 func (s *Settings) Init() error {
 	// Get the current state of the terminal.
 	// Actually, this will get the configuration for the file descriptor.
-	termios, err := unix.IoctlGetTermios(s.fd, unix.TIOCGETA)
+	termios, err := unix.IoctlGetTermios(s.fd, ioctlGetTermios)
 	if err != nil {
 		return err
 	}
@@ -43,23 +41,92 @@ func (s *Settings) Init() error {
 }
 
 func (s *Settings) CookIt() error {
-	icanon := msg{true}
-	return s.set(&icanon, nil)
+	return s.set(ioctlSetTermios, setIcanon)
 }
 
+// UnCookIt disables line buffering.
+// Any input typed so far is kept, and read byte by byte from now on.
 func (s *Settings) UnCookIt() error {
-	icanon := msg{false}
-	return s.set(&icanon, nil)
+	return s.set(ioctlSetTermios, setNoIcanon)
 }
 
 func (s *Settings) Echo() error {
-	echo := msg{true}
-	return s.set(nil, &echo)
+	return s.set(ioctlSetTermios, setEcho)
 }
 
 func (s *Settings) NoEcho() error {
-	echo := msg{false}
-	return s.set(nil, &echo)
+	return s.set(ioctlSetTermios, setNoEcho)
+}
+
+// MakeRaw puts the terminal in raw mode, like cfmakeraw(3) does.
+// Input is available byte by byte, nothing is echoed, and neither the input
+// nor the output are processed in any way: Ctrl-C, Ctrl-Z, Ctrl-S, etc. are
+// delivered to your program as plain bytes.
+// Use Restore to go back to the previous state.
+func (s *Settings) MakeRaw() error {
+	return s.set(ioctlSetTermiosFlush, setRaw)
+}
+
+// Raw reports whether the terminal is in raw mode, as set by MakeRaw.
+func (s *Settings) Raw() bool {
+	return !s.Cooked() && !s.Echoing() && !s.Signalling() && !s.ExtendingInput() &&
+		!s.FlowControlling() && !s.TranslatingCR() && !s.PostProcessing()
+}
+
+// Signals makes the terminal generate signals for INTR (Ctrl-C), QUIT (Ctrl-\)
+// and SUSP (Ctrl-Z).
+func (s *Settings) Signals() error {
+	return s.set(ioctlSetTermios, setIsig)
+}
+
+// NoSignals delivers INTR, QUIT and SUSP characters as input instead.
+func (s *Settings) NoSignals() error {
+	return s.set(ioctlSetTermios, setNoIsig)
+}
+
+// FlowControl enables software flow control (Ctrl-S/Ctrl-Q).
+func (s *Settings) FlowControl() error {
+	return s.set(ioctlSetTermios, setIxon)
+}
+
+// NoFlowControl disables software flow control, so Ctrl-S and Ctrl-Q are
+// delivered as input.
+func (s *Settings) NoFlowControl() error {
+	return s.set(ioctlSetTermios, setNoIxon)
+}
+
+// TranslateCR translates carriage returns into newlines on input.
+func (s *Settings) TranslateCR() error {
+	return s.set(ioctlSetTermios, setIcrnl)
+}
+
+// NoTranslateCR delivers carriage returns as they are.
+// Enter will be read as '\r' instead of '\n'.
+func (s *Settings) NoTranslateCR() error {
+	return s.set(ioctlSetTermios, setNoIcrnl)
+}
+
+// PostProcess enables implementation-defined output processing
+// (i.e. "\n" is written as "\r\n").
+func (s *Settings) PostProcess() error {
+	return s.set(ioctlSetTermios, setOpost)
+}
+
+// NoPostProcess writes the output exactly as it is.
+// You'll need to write "\r\n" to start a new line.
+func (s *Settings) NoPostProcess() error {
+	return s.set(ioctlSetTermios, setNoOpost)
+}
+
+// ExtendedInput enables implementation-defined input processing
+// (i.e. Ctrl-V to insert the next character literally).
+func (s *Settings) ExtendedInput() error {
+	return s.set(ioctlSetTermios, setIexten)
+}
+
+// NoExtendedInput disables implementation-defined input processing.
+func (s *Settings) NoExtendedInput() error {
+	return s.set(ioctlSetTermios, setNoIexten)
 }
 
 func (s *Settings) Echoing() bool {
@@ -72,7 +139,123 @@ func (s *Settings) Cooked() bool {
 	return flag == unix.ICANON
 }
 
-// TODO: How should we handle "resize"?
+// Signalling reports whether Ctrl-C, Ctrl-\ and Ctrl-Z generate signals.
+func (s *Settings) Signalling() bool {
+	flag := s.current.Lflag & unix.ISIG
+	return flag == unix.ISIG
+}
+
+// FlowControlling reports whether software flow control is enabled.
+func (s *Settings) FlowControlling() bool {
+	flag := s.current.Iflag & unix.IXON
+	return flag == unix.IXON
+}
+
+// TranslatingCR reports whether carriage returns are translated into newlines on input.
+func (s *Settings) TranslatingCR() bool {
+	flag := s.current.Iflag & unix.ICRNL
+	return flag == unix.ICRNL
+}
+
+// PostProcessing reports whether the output is being processed.
+func (s *Settings) PostProcessing() bool {
+	flag := s.current.Oflag & unix.OPOST
+	return flag == unix.OPOST
+}
+
+// ExtendingInput reports whether implementation-defined input processing is enabled.
+func (s *Settings) ExtendingInput() bool {
+	flag := s.current.Lflag & unix.IEXTEN
+	return flag == unix.IEXTEN
+}
+
+// IsTerminal reports whether the file descriptor fd refers to a terminal.
+func IsTerminal(fd int) bool {
+	_, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	return err == nil
+}
+
+// Read behaviour.
+//
+// These only have an effect when the terminal is not cooked (see UnCookIt and MakeRaw).
+
+// vtimeUnit is the resolution of VTIME: tenths of a second.
+const vtimeUnit = 100 * time.Millisecond
+
+// ReadTimeout makes reads return as soon as there's some input, or with zero
+// bytes after waiting "d" for it.
+// The timeout has a resolution of a tenth of a second and is rounded up.
+// It can't be longer than 25.5 seconds. A zero timeout is the same as Poll.
+func (s *Settings) ReadTimeout(d time.Duration) error {
+	vtime, err := toVTime(d)
+	if err != nil {
+		return err
+	}
+	return s.set(ioctlSetTermios, setRead(0, vtime))
+}
+
+// Poll makes reads return immediately, with whatever input is available (maybe none).
+func (s *Settings) Poll() error {
+	return s.set(ioctlSetTermios, setRead(0, 0))
+}
+
+// Block makes reads wait until at least one byte is available.
+// This is the default after UnCookIt or MakeRaw.
+func (s *Settings) Block() error {
+	return s.set(ioctlSetTermios, setRead(1, 0))
+}
+
+// ReadAtLeast sets VMIN and VTIME directly: reads wait for "n" bytes, but once
+// the first one arrives they return after "d" without further input.
+// A zero "d" waits for the "n" bytes forever.
+func (s *Settings) ReadAtLeast(n int, d time.Duration) error {
+	if n < 0 || n > 255 {
+		return fmt.Errorf("err: %d is not a valid minimum read (0-255)", n)
+	}
+	vtime, err := toVTime(d)
+	if err != nil {
+		return err
+	}
+	return s.set(ioctlSetTermios, setRead(uint8(n), vtime))
+}
+
+// VMin returns the minimum number of bytes a read waits for.
+func (s *Settings) VMin() int {
+	return int(s.current.Cc[unix.VMIN])
+}
+
+// VTime returns how long a read waits for input.
+func (s *Settings) VTime() time.Duration {
+	return time.Duration(s.current.Cc[unix.VTIME]) * vtimeUnit
+}
+
+// WaitInput waits up to "d" for some input to be available, and reports
+// whether there is any, so the next read won't block.
+// Unlike ReadTimeout, it works the same in any mode and doesn't change the
+// settings. It has a resolution of a millisecond.
+func (s *Settings) WaitInput(d time.Duration) (bool, error) {
+	deadline := time.Now().Add(d)
+	fds := []unix.PollFd{{Fd: int32(s.fd), Events: unix.POLLIN}}
+
+	for {
+		left := max(time.Until(deadline), 0)
+		// Round up, so we don't spin when there's less than a millisecond left.
+		ms := int((left + time.Millisecond - 1) / time.Millisecond)
+
+		n, err := unix.Poll(fds, ms)
+		if err == unix.EINTR {
+			// Interrupted by a signal (the Go runtime sends a lot of them).
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		return n > 0, nil
+	}
+}
+
+// Size returns the terminal dimensions, in characters.
+// It's read on every call: to get notified when it changes, see termy's Display.NotifyResize.
 func (s *Settings) Size() (rows, cols int, err error) {
 	ws, err := unix.IoctlGetWinsize(s.fd, unix.TIOCGWINSZ)
 	if err != nil {
@@ -88,13 +271,32 @@ func (s *Settings) Restore() error {
 	if s.saved == nil {
 		return errors.New("err: terminal stated was not previously saved")
 	}
-	err := unix.IoctlSetTermios(s.fd, unix.TIOCSETA, s.saved)
+	// Let any pending output be written with the current settings first.
+	err := unix.IoctlSetTermios(s.fd, ioctlSetTermiosDrain, s.saved)
 	if err != nil {
 		return err
 	}
+	// Keep track of the changes, so the queries are still accurate.
+	s.current = *s.saved
 	return nil
 }
 
+// Suspend sets the terminal to its previous state, like Restore, but keeps
+// track of the current settings so they can be applied again with Resume.
+// Use it when your program is about to be stopped (i.e. on SIGTSTP).
+func (s *Settings) Suspend() error {
+	if s.saved == nil {
+		return errors.New("err: terminal stated was not previously saved")
+	}
+	return unix.IoctlSetTermios(s.fd, ioctlSetTermiosDrain, s.saved)
+}
+
+// Resume applies the current settings again, after Suspend or after
+// someone else messed with the terminal (i.e. your shell, while we were stopped).
+func (s *Settings) Resume() error {
+	return s.set(ioctlSetTermiosFlush)
+}
+
 // -------- Internal -------- //
 
 func setEcho(termios *unix.Termios) {
@@ -113,27 +315,84 @@ func setNoIcanon(termios *unix.Termios) {
 	termios.Lflag &^= unix.ICANON
 }
 
-func (s *Settings) set(icanon, echo *msg) error {
-	// Make a copy in case the operation fails.
-	termios := s.current
+func setIsig(termios *unix.Termios) {
+	termios.Lflag |= unix.ISIG
+}
 
-	if echo != nil {
-		if echo.on {
-			setEcho(&termios)
-		} else {
-			setNoEcho(&termios)
-		}
+func setNoIsig(termios *unix.Termios) {
+	termios.Lflag &^= unix.ISIG
+}
+
+func setIexten(termios *unix.Termios) {
+	termios.Lflag |= unix.IEXTEN
+}
+
+func setNoIexten(termios *unix.Termios) {
+	termios.Lflag &^= unix.IEXTEN
+}
+
+func setIxon(termios *unix.Termios) {
+	termios.Iflag |= unix.IXON
+}
+
+func setNoIxon(termios *unix.Termios) {
+	termios.Iflag &^= unix.IXON
+}
+
+func setIcrnl(termios *unix.Termios) {
+	termios.Iflag |= unix.ICRNL
+}
+
+func setNoIcrnl(termios *unix.Termios) {
+	termios.Iflag &^= unix.ICRNL
+}
+
+func setOpost(termios *unix.Termios) {
+	termios.Oflag |= unix.OPOST
+}
+
+func setNoOpost(termios *unix.Termios) {
+	termios.Oflag &^= unix.OPOST
+}
+
+// setRaw does the same as cfmakeraw(3).
+func setRaw(termios *unix.Termios) {
+	termios.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP |
+		unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	termios.Oflag &^= unix.OPOST
+	termios.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	termios.Cflag &^= unix.CSIZE | unix.PARENB
+	termios.Cflag |= unix.CS8
+	// One byte at a time, with no timeout.
+	termios.Cc[unix.VMIN] = 1
+	termios.Cc[unix.VTIME] = 0
+}
+
+func setRead(vmin, vtime uint8) func(*unix.Termios) {
+	return func(termios *unix.Termios) {
+		termios.Cc[unix.VMIN] = vmin
+		termios.Cc[unix.VTIME] = vtime
 	}
+}
 
-	if icanon != nil {
-		if icanon.on {
-			setIcanon(&termios)
-		} else {
-			setNoIcanon(&termios)
-		}
+// toVTime converts d into tenths of a second, rounding up.
+func toVTime(d time.Duration) (uint8, error) {
+	if d < 0 || d > 255*vtimeUnit {
+		return 0, fmt.Errorf("err: %v is not a valid read timeout (0-25.5s)", d)
+	}
+	return uint8((d + vtimeUnit - 1) / vtimeUnit), nil
+}
+
+// set applies the requested changes using the ioctl request "req".
+func (s *Settings) set(req uint, changes ...func(*unix.Termios)) error {
+	// Make a copy in case the operation fails.
+	termios := s.current
+
+	for _, change := range changes {
+		change(&termios)
 	}
 
-	err := unix.IoctlSetTermios(s.fd, unix.TIOCSETA, &termios)
+	err := unix.IoctlSetTermios(s.fd, req, &termios)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,481 @@
+//go:build linux
+
+package term
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mec-nyan/termy/internal/pty"
+	"golang.org/x/sys/unix"
+)
+
+// newPty returns Settings for the slave side of a new pty, plus the master side.
+func newPty(t *testing.T) (*Settings, *os.File) {
+	t.Helper()
+
+	master, slave, err := pty.Open()
+	if err != nil {
+		t.Skipf("can't open a pty: %v", err)
+	}
+	t.Cleanup(func() {
+		slave.Close()
+		master.Close()
+	})
+
+	s := New(int(slave.Fd()))
+	if err := s.Init(); err != nil {
+		t.Fatalf("err: Init: %v", err)
+	}
+
+	return s, master
+}
+
+// lflag reads the local flags straight from the kernel.
+func lflag(t *testing.T, s *Settings) uint32 {
+	t.Helper()
+
+	termios, err := unix.IoctlGetTermios(s.fd, ioctlGetTermios)
+	if err != nil {
+		t.Fatalf("err: can't get termios: %v", err)
+	}
+	return uint32(termios.Lflag)
+}
+
+func TestSettings(t *testing.T) {
+	// Given
+	cases := []struct {
+		name   string
+		action func(*Settings) error
+		// Flags that must be set/clear afterwards.
+		set, clear uint32
+	}{
+		{
+			name:   "UnCookIt",
+			action: (*Settings).UnCookIt,
+			set:    unix.ECHO,
+			clear:  unix.ICANON,
+		},
+		{
+			name: "CookIt",
+			action: func(s *Settings) error {
+				s.UnCookIt()
+				return s.CookIt()
+			},
+			set: unix.ICANON,
+		},
+		{
+			name:   "NoEcho",
+			action: (*Settings).NoEcho,
+			set:    unix.ICANON,
+			clear:  unix.ECHO,
+		},
+		{
+			name: "Echo",
+			action: func(s *Settings) error {
+				s.NoEcho()
+				return s.Echo()
+			},
+			set: unix.ECHO,
+		},
+		{
+			name: "Both",
+			action: func(s *Settings) error {
+				if err := s.UnCookIt(); err != nil {
+					return err
+				}
+				return s.NoEcho()
+			},
+			clear: unix.ICANON | unix.ECHO,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, _ := newPty(t)
+
+			if err := c.action(s); err != nil {
+				t.Fatalf("err: %v", err)
+			}
+
+			got := lflag(t, s)
+			if got&c.set != c.set {
+				t.Errorf("err: want flags %#x set, got %#x", c.set, got)
+			}
+			if got&c.clear != 0 {
+				t.Errorf("err: want flags %#x clear, got %#x", c.clear, got)
+			}
+			// Our own view should match the kernel's.
+			if s.Cooked() != (got&unix.ICANON != 0) {
+				t.Errorf("err: Cooked() = %v, kernel says %#x", s.Cooked(), got)
+			}
+			if s.Echoing() != (got&unix.ECHO != 0) {
+				t.Errorf("err: Echoing() = %v, kernel says %#x", s.Echoing(), got)
+			}
+		})
+	}
+}
+
+func TestRestore(t *testing.T) {
+	s, _ := newPty(t)
+	want := lflag(t, s)
+
+	s.UnCookIt()
+	s.NoEcho()
+	if lflag(t, s) == want {
+		t.Fatal("err: settings didn't change")
+	}
+
+	if err := s.Restore(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if got := lflag(t, s); got != want {
+		t.Errorf("err: want (%#x), got (%#x)", want, got)
+	}
+}
+
+func TestRestoreNotSaved(t *testing.T) {
+	s := New(-1)
+	if err := s.Restore(); err == nil {
+		t.Error("err: want an error, got nil")
+	}
+}
+
+func TestUnCookedRead(t *testing.T) {
+	s, master := newPty(t)
+	if err := s.UnCookIt(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Without a newline, a cooked terminal would keep the byte to itself.
+	master.Write([]byte("x"))
+
+	got := make(chan byte)
+	go func() {
+		buf := make([]byte, 1)
+		unix.Read(s.fd, buf)
+		got <- buf[0]
+	}()
+
+	select {
+	case b := <-got:
+		if b != 'x' {
+			t.Errorf("err: want ('x'), got (%q)", b)
+		}
+	case <-time.After(time.Second):
+		t.Error("err: read didn't return, is the terminal still cooked?")
+	}
+}
+
+func TestUnCookKeepsInput(t *testing.T) {
+	s, master := newPty(t)
+
+	// Typed while cooked, not a whole line yet.
+	master.Write([]byte("ab"))
+	time.Sleep(10 * time.Millisecond)
+
+	if err := s.UnCookIt(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if ok, err := s.WaitInput(time.Second); !ok || err != nil {
+		t.Fatalf("err: want the input kept, got (%v, %v)", ok, err)
+	}
+	buf := make([]byte, 2)
+	if n, _ := unix.Read(s.fd, buf); string(buf[:n]) != "ab" {
+		t.Errorf("err: want (ab), got (%q)", buf[:n])
+	}
+}
+
+// termios reads the whole structure straight from the kernel.
+func termios(t *testing.T, s *Settings) *unix.Termios {
+	t.Helper()
+
+	termios, err := unix.IoctlGetTermios(s.fd, ioctlGetTermios)
+	if err != nil {
+		t.Fatalf("err: can't get termios: %v", err)
+	}
+	return termios
+}
+
+func TestToggles(t *testing.T) {
+	// Given
+	cases := []struct {
+		name  string
+		on    func(*Settings) error
+		off   func(*Settings) error
+		query func(*Settings) bool
+		// kernel tells whether the flag is set according to the kernel.
+		kernel func(*unix.Termios) bool
+	}{
+		{
+			name:   "ISIG",
+			on:     (*Settings).Signals,
+			off:    (*Settings).NoSignals,
+			query:  (*Settings).Signalling,
+			kernel: func(t *unix.Termios) bool { return t.Lflag&unix.ISIG != 0 },
+		},
+		{
+			name:   "IXON",
+			on:     (*Settings).FlowControl,
+			off:    (*Settings).NoFlowControl,
+			query:  (*Settings).FlowControlling,
+			kernel: func(t *unix.Termios) bool { return t.Iflag&unix.IXON != 0 },
+		},
+		{
+			name:   "ICRNL",
+			on:     (*Settings).TranslateCR,
+			off:    (*Settings).NoTranslateCR,
+			query:  (*Settings).TranslatingCR,
+			kernel: func(t *unix.Termios) bool { return t.Iflag&unix.ICRNL != 0 },
+		},
+		{
+			name:   "OPOST",
+			on:     (*Settings).PostProcess,
+			off:    (*Settings).NoPostProcess,
+			query:  (*Settings).PostProcessing,
+			kernel: func(t *unix.Termios) bool { return t.Oflag&unix.OPOST != 0 },
+		},
+		{
+			name:   "IEXTEN",
+			on:     (*Settings).ExtendedInput,
+			off:    (*Settings).NoExtendedInput,
+			query:  (*Settings).ExtendingInput,
+			kernel: func(t *unix.Termios) bool { return t.Lflag&unix.IEXTEN != 0 },
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, _ := newPty(t)
+			initial := c.query(s)
+
+			for _, on := range []bool{false, true, false} {
+				action := c.off
+				if on {
+					action = c.on
+				}
+				if err := action(s); err != nil {
+					t.Fatalf("err: %v", err)
+				}
+				if got := c.kernel(termios(t, s)); got != on {
+					t.Errorf("err: kernel: want (%v), got (%v)", on, got)
+				}
+				if got := c.query(s); got != on {
+					t.Errorf("err: query: want (%v), got (%v)", on, got)
+				}
+			}
+
+			if err := s.Restore(); err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			if got := c.kernel(termios(t, s)); got != initial {
+				t.Errorf("err: restore: want (%v), got (%v)", initial, got)
+			}
+		})
+	}
+}
+
+func TestMakeRaw(t *testing.T) {
+	s, _ := newPty(t)
+	want := *termios(t, s)
+
+	if s.Raw() {
+		t.Fatal("err: a new pty shouldn't be in raw mode")
+	}
+
+	if err := s.MakeRaw(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	got := termios(t, s)
+	if got.Lflag&(unix.ECHO|unix.ICANON|unix.ISIG|unix.IEXTEN) != 0 {
+		t.Errorf("err: local flags not cleared: %#x", got.Lflag)
+	}
+	if got.Iflag&(unix.IXON|unix.ICRNL) != 0 {
+		t.Errorf("err: input flags not cleared: %#x", got.Iflag)
+	}
+	if got.Oflag&unix.OPOST != 0 {
+		t.Errorf("err: output flags not cleared: %#x", got.Oflag)
+	}
+	if got.Cflag&unix.CSIZE != unix.CS8 {
+		t.Errorf("err: want 8 bit characters, got %#x", got.Cflag&unix.CSIZE)
+	}
+	if !s.Raw() {
+		t.Error("err: Raw() = false after MakeRaw")
+	}
+
+	if err := s.Restore(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	got = termios(t, s)
+	if got.Iflag != want.Iflag || got.Oflag != want.Oflag || got.Lflag != want.Lflag || got.Cflag != want.Cflag {
+		t.Errorf("err: want (%+v), got (%+v)", want, *got)
+	}
+	if s.Raw() {
+		t.Error("err: Raw() = true after Restore")
+	}
+}
+
+// timedRead reads once from the terminal and tells how long it took.
+func timedRead(t *testing.T, s *Settings) (int, time.Duration) {
+	t.Helper()
+
+	type result struct {
+		n       int
+		elapsed time.Duration
+	}
+	done := make(chan result)
+	go func() {
+		buf := make([]byte, 16)
+		start := time.Now()
+		n, _ := unix.Read(s.fd, buf)
+		done <- result{n, time.Since(start)}
+	}()
+
+	select {
+	case r := <-done:
+		return r.n, r.elapsed
+	case <-time.After(5 * time.Second):
+		t.Fatal("err: read didn't return")
+	}
+	return 0, 0
+}
+
+func TestReadTimeout(t *testing.T) {
+	s, master := newPty(t)
+	s.UnCookIt()
+
+	if err := s.ReadTimeout(200 * time.Millisecond); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if got := termios(t, s).Cc[unix.VTIME]; got != 2 {
+		t.Errorf("err: kernel VTIME: want (2), got (%d)", got)
+	}
+	if s.VMin() != 0 || s.VTime() != 200*time.Millisecond {
+		t.Errorf("err: want (0, 200ms), got (%d, %v)", s.VMin(), s.VTime())
+	}
+
+	// No input: the read gives up after the timeout.
+	n, elapsed := timedRead(t, s)
+	if n != 0 {
+		t.Errorf("err: want (0) bytes, got (%d)", n)
+	}
+	if elapsed < 150*time.Millisecond {
+		t.Errorf("err: read returned too early (%v)", elapsed)
+	}
+
+	// Some input: the read returns straight away.
+	master.Write([]byte("abc"))
+	n, _ = timedRead(t, s)
+	if n != 3 {
+		t.Errorf("err: want (3) bytes, got (%d)", n)
+	}
+}
+
+func TestReadTimeoutRounding(t *testing.T) {
+	s, _ := newPty(t)
+
+	s.ReadTimeout(time.Millisecond)
+	if got := s.VTime(); got != 100*time.Millisecond {
+		t.Errorf("err: want (100ms), got (%v)", got)
+	}
+
+	if err := s.ReadTimeout(time.Minute); err == nil {
+		t.Error("err: want an error for a timeout too long, got nil")
+	}
+	if err := s.ReadAtLeast(256, 0); err == nil {
+		t.Error("err: want an error for a minimum too big, got nil")
+	}
+}
+
+func TestPollAndBlock(t *testing.T) {
+	s, _ := newPty(t)
+	s.UnCookIt()
+
+	if err := s.Poll(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if s.VMin() != 0 || s.VTime() != 0 {
+		t.Errorf("err: want (0, 0s), got (%d, %v)", s.VMin(), s.VTime())
+	}
+	n, elapsed := timedRead(t, s)
+	if n != 0 || elapsed > 100*time.Millisecond {
+		t.Errorf("err: want an immediate empty read, got (%d) bytes after (%v)", n, elapsed)
+	}
+
+	if err := s.Block(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	got := termios(t, s)
+	if got.Cc[unix.VMIN] != 1 || got.Cc[unix.VTIME] != 0 {
+		t.Errorf("err: kernel: want (1, 0), got (%d, %d)", got.Cc[unix.VMIN], got.Cc[unix.VTIME])
+	}
+
+	if err := s.ReadAtLeast(4, 300*time.Millisecond); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if s.VMin() != 4 || s.VTime() != 300*time.Millisecond {
+		t.Errorf("err: want (4, 300ms), got (%d, %v)", s.VMin(), s.VTime())
+	}
+}
+
+func TestWaitInput(t *testing.T) {
+	s, master := newPty(t)
+	s.UnCookIt()
+
+	// No input: give up after the timeout.
+	start := time.Now()
+	ready, err := s.WaitInput(50 * time.Millisecond)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if ready {
+		t.Error("err: want no input, got some")
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("err: returned too early (%v)", elapsed)
+	}
+
+	// Some input: return straight away, and leave it for the next read.
+	master.Write([]byte("x"))
+	ready, err = s.WaitInput(time.Second)
+	if err != nil || !ready {
+		t.Fatalf("err: want input, got (%v, %v)", ready, err)
+	}
+	if n, _ := timedRead(t, s); n != 1 {
+		t.Errorf("err: want (1) byte, got (%d)", n)
+	}
+
+	// A zero timeout just checks.
+	if ready, _ := s.WaitInput(0); ready {
+		t.Error("err: want no input after reading it, got some")
+	}
+}
+
+func TestSuspendResume(t *testing.T) {
+	s, _ := newPty(t)
+	want := lflag(t, s)
+
+	s.UnCookIt()
+	s.NoEcho()
+	changed := lflag(t, s)
+
+	if err := s.Suspend(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if got := lflag(t, s); got != want {
+		t.Errorf("err: suspend: want (%#x), got (%#x)", want, got)
+	}
+	// We still know what the settings should be.
+	if s.Cooked() || s.Echoing() {
+		t.Error("err: suspend forgot the current settings")
+	}
+
+	if err := s.Resume(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if got := lflag(t, s); got != changed {
+		t.Errorf("err: resume: want (%#x), got (%#x)", changed, got)
+	}
+}
@@ -1,12 +1,14 @@
 package termy
 
 import (
-	"fmt"
 	"strconv"
+	"sync"
 
 	"github.com/mec-nyan/termy/byteme"
+	"github.com/mec-nyan/termy/input"
 	"github.com/mec-nyan/termy/printer"
 	"github.com/mec-nyan/termy/term"
+	"github.com/mec-nyan/termy/tty"
 )
 
 const (
@@ -20,6 +22,21 @@ const (
 	// These flags lets us keep track of some internal state.
 	altBuf uint = 1 << iota
 	altCharSet
+	hiddenCur
+	// Some text attributes (colours, styles) were sent.
+	attributes
+	// Some kitty keyboard flags were pushed.
+	kittyKeyboard
+	// Mouse tracking modes and encodings.
+	mouseX10
+	mouseNormal
+	mouseButtonEvent
+	mouseAnyEvent
+	mouseSGR
+	mouseSGRPixels
+	bracketedPaste
+	focusReporting
+	syncOutput
 )
 
 // Display takes care of handling your terminal and setting things up for your application.
@@ -30,25 +47,70 @@ type Display struct {
 	term.Settings
 	printer.Printer
 	flags uint
+	// modes is a stack with every change we need to undo on Restore.
+	modes []mode
+	// mu guards flags and modes, as Restore can be called from a signal handler,
+	// and the event loop state.
+	mu sync.Mutex
+	// noTermios is set when we don't handle the terminal settings (see WithoutTermios).
+	noTermios bool
+	// in decodes the user input, once inOnce sets it up (see input).
+	in     *input.Reader
+	inOnce sync.Once
+	// kitty holds the keyboard flags we've pushed, so we can pop them.
+	kitty []input.KittyFlags
+	// loop is the running event loop, if any (see Events).
+	loop      *eventLoop
+	eventsErr error
+	// pending holds the events a stopped loop (or a query) didn't deliver.
+	pending []input.Event
+	// caps is what the terminal told us about itself (see Probe).
+	caps Capabilities
+	// colours caches the colours the terminal reported, by OSC code (see BackgroundColour).
+	colours map[string]colourReply
+	// syncProbed is set once SyncSupported asked the terminal, so it's not
+	// asked again if it doesn't answer.
+	syncProbed bool
+	// syncDepth is how many BeginSync are waiting for their EndSync.
+	syncDepth int
 }
 
 // NewDisplay initialise a new Display structure with the default settings.
 // For now I'm keeping that name "NewDisplay" instead of just "New" since it's
 // more explicit about what it really does.
 func NewDisplay() (*Display, error) {
-	printer := printer.New()
+	return NewDisplayWith()
+}
 
-	settings := term.New(int(printer.Stdout.Fd()))
+// NewDisplayTTY initialise a new Display that talks to the controlling terminal
+// (/dev/tty) instead of the process' stdin and stdout.
+// Use it for interactive programs that may have their input or output redirected,
+// i.e. a picker used as `ls | mytool > out.txt`: stdin and stdout are still there
+// for your data.
+// Call Close when you're done, to restore the terminal and close /dev/tty.
+func NewDisplayTTY() (*Display, error) {
+	t, err := tty.Open()
+	if err != nil {
+		return nil, err
+	}
 
-	err := settings.Init()
+	d, err := newDisplay(t, config{fd: -1})
 	if err != nil {
+		t.Close()
 		return nil, err
 	}
 
-	return &Display{
-		Settings: *settings,
-		Printer:  printer,
-	}, nil
+	return d, nil
+}
+
+// Close restores the terminal (see Restore) and closes /dev/tty, if it was
+// opened by NewDisplayTTY.
+func (d *Display) Close() error {
+	err := d.Restore()
+	if closeErr := d.TTY.Close(); err == nil {
+		err = closeErr
+	}
+	return err
 }
 
 ///////////////////////////////
@@ -77,8 +139,8 @@ func (d *Display) ClearToEOS() {
 
 // Clear the screen and move the cursor to the upper left corner.
 func (d *Display) ClearScreen() {
-	Home()
-	ClearToEOS()
+	d.Home()
+	d.ClearToEOS()
 }
 
 // Save the current cursor position.
@@ -123,30 +185,22 @@ func (d *Display) Left() {
 
 // Move cursor "lines" rows up.
 func (d *Display) MoveUp(lines int) {
-	for i := 0; i < lines; i++ {
-		Up()
-	}
+	d.move(lines, 'A')
 }
 
 // Move cursor "lines" rows down.
 func (d *Display) MoveDown(lines int) {
-	for i := 0; i < lines; i++ {
-		Down()
-	}
+	d.move(lines, 'B')
 }
 
 // Move cursor "cols" columns to the right.
 func (d *Display) MoveRight(cols int) {
-	for i := 0; i < cols; i++ {
-		Right()
-	}
+	d.move(cols, 'C')
 }
 
 // Move cursor "cols" columns to the left.
 func (d *Display) MoveLeft(cols int) {
-	for i := 0; i < cols; i++ {
-		Left()
-	}
+	d.move(cols, 'D')
 }
 
 // Move cursor to line "y" col "x"
@@ -156,12 +210,13 @@ func (d *Display) MoveTo(x, y int) {
 
 // Make cursor invisible.
 func (d *Display) HideCur() {
-	d.write(_csi + "?25l")
+	// Make sure it's visible again on Restore.
+	d.setMode(hiddenCur, _csi+"?25l", _csi+"?25h")
 }
 
 // Make cursor visible.
 func (d *Display) ShowCur() {
-	d.write(_csi + "?25h")
+	d.resetMode(hiddenCur, _csi+"?25h")
 }
 
 // Enter alt buffer mode.
@@ -169,17 +224,15 @@ func (d *Display) EnterAltBuf() {
 	if d.inAltBuf() {
 		return
 	}
-	d.write(_csi + "?1049h")
 	// Set flag to save state.
-	d.flags |= altBuf
+	d.setMode(altBuf, _csi+"?1049h", _csi+"?1049l")
 }
 
 // Exit alt buffer mode.
 func (d *Display) ExitAltBuf() {
 	if d.inAltBuf() {
-		d.write(_csi + "?1049l")
 		// Clear flag.
-		d.flags &^= altBuf
+		d.resetMode(altBuf, _csi+"?1049l")
 	}
 }
 
@@ -188,15 +241,13 @@ func (d *Display) EnterACS() {
 	if d.inAltCharSet() {
 		return
 	}
-	d.write(_esc + "(0")
-	d.flags |= altCharSet
+	d.setMode(altCharSet, _esc+"(0", _esc+"(B")
 }
 
 // Exit alternate character set mode.
 func (d *Display) ExitACS() {
 	if d.inAltCharSet() {
-		d.write(_esc + "(B")
-		d.flags &^= altCharSet
+		d.resetMode(altCharSet, _esc+"(B")
 	}
 }
 
@@ -313,6 +364,14 @@ func (d *Display) SetBgHex(colour string) *Display {
 	return d
 }
 
+// SetEscapes overrides the decision of whether to send escape sequences or not.
+// When they're disabled, cursor movements, colours, styles, etc. do nothing,
+// but text is still printed. See printer.DetectEscapes.
+func (d *Display) SetEscapes(on bool) *Display {
+	d.Printer.SetEscapes(on)
+	return d
+}
+
 // Code generates the code for the currently selected colours and/or style.
 // It doesn't prepend the CSI.
 // NOTE: This function may not need to be exported.
@@ -333,7 +392,16 @@ func (d *Display) Code() string {
 
 // Send actually sends the in-band signal to the terminal/selected writer.
 func (d *Display) Send() {
-	d.Stdout.Write(byteme.UnsafeStrToBytes(d.escaped()))
+	code := d.escaped()
+	if len(code) == 0 || !d.Escapes() {
+		return
+	}
+	d.Write(byteme.UnsafeStrToBytes(code))
+	// Leave the default attributes on Restore.
+	d.enter(attributes,
+		func() { d.write(_csi + "0m") },
+		func() { d.write(d.escaped()) },
+	)
 }
 
 // Text style.
@@ -448,7 +516,7 @@ func (d *Display) CurlyUnderline() *Display {
 
 // PrintBytes prints out a slice of bytes.
 func (d *Display) PrintBytes(b []byte) (int, error) {
-	return d.Stdout.Write(b)
+	return d.Write(b)
 }
 
 // PrintBytesAt prints a slice of byte at (x, y).
@@ -458,14 +526,18 @@ func (d *Display) PrintBytesAt(x, y int, b []byte) (int, error) {
 	return d.PrintBytes(b)
 }
 
-// TODO: PrintNBytes should count COLUMNS and not BYTES nor CHARACTERS.
+// PrintNBytes prints as much of b as fits in "cols" columns (see the width
+// package and SetZWJPolicy), never splitting a character: a wide one that
+// doesn't fit is left out. It returns how many bytes of b were written.
 func (d *Display) PrintNBytes(cols int, b []byte) (int, error) {
-	return 0, nil
+	n, _ := d.Measure().Truncate(b, cols)
+	return d.PrintBytes(b[:n])
 }
 
-// TODO: PrintNBytes should count COLUMNS and not BYTES nor CHARACTERS.
+// PrintNBytesAt prints as much of b as fits in "cols" columns at (x, y).
 func (d *Display) PrintNBytesAt(x, y, cols int, b []byte) (int, error) {
-	return 0, nil
+	d.MoveTo(x, y)
+	return d.PrintNBytes(cols, b)
 }
 
 // Print prints a utf-8 encoded string.
@@ -480,33 +552,24 @@ func (d *Display) PrintAt(x, y int, s string) (int, error) {
 	return d.PrintBytesAt(x, y, byteme.UnsafeStrToBytes(s))
 }
 
-func (d *Display) CurPos() (int, int) {
-	// We don't want our terminal to print that to stdout.
-	if d.Echoing() {
-		d.NoEcho()
-		defer d.Echo()
-	}
-	// Not quite sure if we need this, but it seems like it.
-	if d.Cooked() {
-		d.UnCookIt()
-		defer d.CookIt()
-	}
-
-	x := 0
-	y := 0
-	// TODO: There has to be a better way using std[out|in] directly.
-	// It will work for now.
-	fmt.Print("\x1b[6n")
-	fmt.Scanf("\x1b[%d;%dR", &y, &x)
-
-	return x, y
-}
-
 // Internal.
 
-// write is a wrapper for Stdout.Write.
+// write is a wrapper for Write (see printer.Printer.Write).
+// It's used for escape sequences only, so nothing is written when they're disabled.
 func (d *Display) write(s string) {
-	d.Stdout.Write(byteme.UnsafeStrToBytes(s))
+	if !d.Escapes() {
+		return
+	}
+	d.Write(byteme.UnsafeStrToBytes(s))
+}
+
+// move moves the cursor n times in the direction "dir" (CUU, CUD, CUF or CUB),
+// in a single sequence.
+func (d *Display) move(n int, dir byte) {
+	if n <= 0 {
+		return
+	}
+	d.write(_csi + strconv.Itoa(n) + string(dir))
 }
 
 // escaped converts the colour and style sequence in an in-band command.
@@ -528,8 +591,7 @@ func (d *Display) escaped() string {
 // If the terminal was put in alt buf mode by any other way (i.e. you print the codes directly)
 // this will not reflect that. (BTW don't do that! Use the methods provided).
 func (d *Display) inAltBuf() bool {
-	f := d.flags & altBuf
-	return f == altBuf
+	return d.is(altBuf)
 }
 
 // Check if the display is in alternate character set mode.
@@ -538,6 +600,5 @@ func (d *Display) inAltBuf() bool {
 // If the terminal was put in ACS mode by any other way (i.e. you print the codes directly)
 // this will not reflect that. (BTW don't do that! Use the methods provided).
 func (d *Display) inAltCharSet() bool {
-	f := d.flags & altCharSet
-	return f == altCharSet
+	return d.is(altCharSet)
 }
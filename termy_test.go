@@ -0,0 +1,44 @@
+package termy
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrintNBytes(t *testing.T) {
+	// Given
+	cases := []struct {
+		text string
+		cols int
+		want string
+	}{
+		{text: "hello", cols: 3, want: "hel"},
+		{text: "hello", cols: 10, want: "hello"},
+		{text: "u 🩷!", cols: 3, want: "u "},
+		{text: "u 🩷!", cols: 4, want: "u 🩷"},
+		{text: "日本語", cols: 5, want: "日本"},
+		{text: "café", cols: 4, want: "café"},
+	}
+
+	for _, c := range cases {
+		var out bytes.Buffer
+		d, err := NewDisplayWith(WithOutput(&out), WithoutTermios(), WithEscapes(true))
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		n, err := d.PrintNBytes(c.cols, []byte(c.text))
+		if err != nil {
+			t.Errorf("err: %v", err)
+		}
+		if got := out.String(); got != c.want || n != len(c.want) {
+			t.Errorf("err: %q, %d: want (%q), got (%q, %d bytes)", c.text, c.cols, c.want, got, n)
+		}
+
+		out.Reset()
+		d.PrintNBytesAt(2, 3, c.cols, []byte(c.text))
+		if got, want := out.String(), "\x1b[3;2H"+c.want; got != want {
+			t.Errorf("err: want (%q), got (%q)", want, got)
+		}
+	}
+}
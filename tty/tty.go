@@ -1,12 +1,21 @@
 package tty
 
-import "os"
+import (
+	"io"
+	"os"
+
+	"github.com/mec-nyan/termy/term"
+)
 
 // TTY provides the means to interact with stdout, stdin and stderr
+// They're usually files, but any reader/writer will do (i.e. a buffer in your tests).
 type TTY struct {
-	Stdout *os.File
-	Stdin  *os.File
-	Stderr *os.File
+	Stdout io.Writer
+	Stdin  io.Reader
+	Stderr io.Writer
+
+	// owned is the file we opened ourselves, if any, and have to close.
+	owned *os.File
 }
 
 // New creates a new TTY with default values for stdin, stdout and stderr.
@@ -17,3 +26,69 @@ func New() TTY {
 		Stderr: os.Stderr,
 	}
 }
+
+// NewWith creates a new TTY using the given streams for stdin, stdout and stderr.
+// They're not closed by Close: they're yours.
+func NewWith(stdin io.Reader, stdout, stderr io.Writer) TTY {
+	return TTY{
+		Stdout: stdout,
+		Stdin:  stdin,
+		Stderr: stderr,
+	}
+}
+
+// Open creates a new TTY that talks to the controlling terminal (/dev/tty)
+// for both input and output, even if the process' stdin or stdout are redirected.
+// That leaves them free to carry your data, i.e. `mytool | less` or `echo x | mytool`.
+// Stderr is left untouched.
+// Remember to call Close when you're done.
+func Open() (TTY, error) {
+	f, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return TTY{}, err
+	}
+
+	return TTY{
+		Stdout: f,
+		Stdin:  f,
+		Stderr: os.Stderr,
+		owned:  f,
+	}, nil
+}
+
+// Close closes the controlling terminal, if it was opened by Open.
+// Otherwise it does nothing.
+func (t TTY) Close() error {
+	if t.owned == nil {
+		return nil
+	}
+	return t.owned.Close()
+}
+
+// Terminals reports whether stdin, stdout and stderr are terminals.
+func (t TTY) Terminals() (stdin, stdout, stderr bool) {
+	return IsTerminal(t.Stdin), IsTerminal(t.Stdout), IsTerminal(t.Stderr)
+}
+
+// Fd returns the file descriptor behind a stream, if it has one (i.e. it's an *os.File).
+func Fd(stream any) (fd int, ok bool) {
+	f, ok := stream.(interface{ Fd() uintptr })
+	if !ok || f == nil {
+		return -1, false
+	}
+	// A nil *os.File still has an Fd method.
+	if file, isFile := f.(*os.File); isFile && file == nil {
+		return -1, false
+	}
+	return int(f.Fd()), true
+}
+
+// IsTerminal reports whether a stream is a terminal.
+// Streams without a file descriptor (i.e. buffers) never are.
+func IsTerminal(stream any) bool {
+	fd, ok := Fd(stream)
+	if !ok {
+		return false
+	}
+	return term.IsTerminal(fd)
+}
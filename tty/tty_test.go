@@ -0,0 +1,78 @@
+//go:build linux
+
+package tty
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/mec-nyan/termy/internal/pty"
+)
+
+func TestIsTerminal(t *testing.T) {
+	master, slave, err := pty.Open()
+	if err != nil {
+		t.Skipf("can't open a pty: %v", err)
+	}
+	defer master.Close()
+	defer slave.Close()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	// Given
+	cases := []struct {
+		name string
+		file *os.File
+		want bool
+	}{
+		{name: "pty", file: slave, want: true},
+		{name: "pipe", file: w, want: false},
+		{name: "nil", file: nil, want: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := IsTerminal(c.file); got != c.want {
+				t.Errorf("err: want (%v), got (%v)", c.want, got)
+			}
+		})
+	}
+
+	// Mixed streams.
+	tty := NewWith(r, slave, w)
+	stdin, stdout, stderr := tty.Terminals()
+	if stdin || !stdout || stderr {
+		t.Errorf("err: want (false, true, false), got (%v, %v, %v)", stdin, stdout, stderr)
+	}
+
+	// We don't close what isn't ours.
+	if err := tty.Close(); err != nil {
+		t.Errorf("err: %v", err)
+	}
+	if !IsTerminal(slave) {
+		t.Error("err: Close closed a file it didn't open")
+	}
+}
+
+func TestOpen(t *testing.T) {
+	tty, err := Open()
+	if errors.Is(err, syscall.ENXIO) {
+		t.Skip("no controlling terminal")
+	}
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer tty.Close()
+
+	stdin, stdout, _ := tty.Terminals()
+	if !stdin || !stdout {
+		t.Errorf("err: want a terminal for stdin and stdout, got (%v, %v)", stdin, stdout)
+	}
+}
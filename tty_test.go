@@ -0,0 +1,77 @@
+//go:build linux
+
+package termy
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/mec-nyan/termy/internal/pty"
+	"github.com/mec-nyan/termy/tty"
+)
+
+// TestNewDisplayTTY runs itself in a child process that has a pty as its
+// controlling terminal, but stdin and stdout redirected.
+func TestNewDisplayTTY(t *testing.T) {
+	if os.Getenv("TERMY_TEST_TTY") == "1" {
+		d, err := NewDisplayTTY()
+		if err != nil {
+			os.Stdout.WriteString(err.Error())
+			os.Exit(1)
+		}
+		defer d.Close()
+
+		d.Print("hello tty")
+
+		stdin, stdout, _ := tty.New().Terminals()
+		if stdin || stdout {
+			os.Stdout.WriteString("std streams are terminals")
+		} else {
+			os.Stdout.WriteString("data")
+		}
+		return
+	}
+
+	master, slave, err := pty.Open()
+	if err != nil {
+		t.Skipf("can't open a pty: %v", err)
+	}
+	defer master.Close()
+	defer slave.Close()
+
+	var out bytes.Buffer
+	cmd := exec.Command(os.Args[0], "-test.run=^TestNewDisplayTTY$")
+	cmd.Env = append(os.Environ(), "TERMY_TEST_TTY=1")
+	cmd.Stdout = &out
+	// The pty becomes fd 3 in the child, and its controlling terminal.
+	cmd.ExtraFiles = []*os.File{slave}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 3}
+
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("err: %v: %s", err, out.String())
+	}
+	if got := out.String(); !strings.HasPrefix(got, "data") {
+		t.Errorf("err: want (data) on stdout, got (%q)", got)
+	}
+
+	// What the display printed went to the terminal.
+	got := make(chan string)
+	go func() {
+		buf := make([]byte, 256)
+		n, _ := master.Read(buf)
+		got <- string(buf[:n])
+	}()
+	select {
+	case s := <-got:
+		if s != "hello tty" {
+			t.Errorf("err: want (hello tty) on the terminal, got (%q)", s)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("err: nothing written to the terminal")
+	}
+}
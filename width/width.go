@@ -0,0 +1,179 @@
+// Package width tells how many columns text takes on a terminal.
+//
+// Most characters take one column, but East Asian characters and emoji take
+// two, and some take none at all: combining marks (i.e. the accent in "é"
+// written as "e" plus U+0301), variation selectors, zero width joiners and
+// control characters.
+//
+// Text is measured one character (grapheme cluster) at a time, not rune by
+// rune. Terminals don't always agree, specially with emoji sequences (see
+// ZWJPolicy), so this is what most of the modern ones do. Ambiguous
+// characters (East Asian Width A) take one column, as they do outside of CJK
+// locales.
+// Escape sequences aren't understood: only their ESC has no width.
+package width
+
+import (
+	"slices"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mec-nyan/termy/byteme"
+	"github.com/mec-nyan/termy/grapheme"
+)
+
+const (
+	zwj  = 0x200D // Zero width joiner.
+	vs16 = 0xFE0F // Variation selector 16: shown as emoji.
+)
+
+// Rune returns how many columns r takes on its own: 0, 1 or 2.
+// Control characters take none, as there's no telling what they'll do.
+func Rune(r rune) int {
+	switch {
+	case r < 0x20 || r >= 0x7F && r < 0xA0:
+		return 0
+	case r < 0x300:
+		// The soft hyphen (U+00AD) is Cf, but terminals show it.
+		return 1
+	case in(zeroWidth, r) || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case in(wide, r):
+		return 2
+	}
+	return 1
+}
+
+// ZWJPolicy is how wide the emoji joined with zero width joiners (i.e. 👩‍👩‍👧,
+// a family) are, as terminals disagree.
+type ZWJPolicy int
+
+const (
+	// ZWJJoined takes them as a single emoji, two columns wide: what the
+	// terminals that know the sequence show.
+	ZWJJoined ZWJPolicy = iota
+	// ZWJSplit takes each emoji in the sequence on its own: what the
+	// terminals that measure rune by rune show.
+	ZWJSplit
+)
+
+// Measure measures text, taking the ZWJ sequences as its policy says.
+// The zero value is what the package functions use.
+type Measure struct {
+	ZWJ ZWJPolicy
+}
+
+// String returns how many columns s takes.
+func String(s string) int {
+	return Measure{}.String(s)
+}
+
+// Bytes returns how many columns the UTF-8 text in b takes.
+func Bytes(b []byte) int {
+	return Measure{}.Bytes(b)
+}
+
+// Truncate returns how many bytes of b fit in "cols" columns, and how many
+// columns they take. It never splits a character: a wide one that doesn't
+// fit is left out, even if there's one column left.
+func Truncate(b []byte, cols int) (n, w int) {
+	return Measure{}.Truncate(b, cols)
+}
+
+// Next returns the size in bytes and the width in columns of the first
+// character (grapheme cluster, see the grapheme package) in b.
+//
+// Its width is the width of its first rune: the marks, joiners and the like
+// after it don't add any. Except that a variation selector 16 (U+FE0F) shows
+// it as an emoji, two columns wide, and so does a pair of regional indicators
+// (a flag). Control characters take no columns, and invalid UTF-8 one per
+// byte (it's usually shown as U+FFFD).
+func Next(b []byte) (size, w int) {
+	return Measure{}.Next(b)
+}
+
+// String returns how many columns s takes.
+func (m Measure) String(s string) int {
+	return m.Bytes(byteme.UnsafeStrToBytes(s))
+}
+
+// Bytes returns how many columns the UTF-8 text in b takes.
+func (m Measure) Bytes(b []byte) int {
+	cols := 0
+	for len(b) > 0 {
+		n, w := m.Next(b)
+		cols += w
+		b = b[n:]
+	}
+	return cols
+}
+
+// Truncate is like the package's Truncate, following m's policy.
+func (m Measure) Truncate(b []byte, cols int) (n, w int) {
+	for n < len(b) {
+		size, cw := m.Next(b[n:])
+		if w+cw > cols {
+			break
+		}
+		n += size
+		w += cw
+	}
+	return n, w
+}
+
+// Next is like the package's Next, following m's policy.
+func (m Measure) Next(b []byte) (size, w int) {
+	size = grapheme.Next(b)
+	return size, m.cluster(b[:size])
+}
+
+// -------- Internal -------- //
+
+// in reports whether r is in one of the sorted intervals.
+func in(table []interval, r rune) bool {
+	_, found := slices.BinarySearchFunc(table, r, func(i interval, r rune) int {
+		switch {
+		case r < i.first:
+			return 1
+		case r > i.last:
+			return -1
+		}
+		return 0
+	})
+	return found
+}
+
+// cluster returns the width of the grapheme cluster c.
+func (m Measure) cluster(c []byte) int {
+	r, size := utf8.DecodeRune(c)
+	if isRegional(r) && size < len(c) {
+		if r2, _ := utf8.DecodeRune(c[size:]); isRegional(r2) {
+			// A flag.
+			return 2
+		}
+	}
+
+	// The parts joined by ZWJs, and the width of the one we're in.
+	w, part := 0, 0
+	for _, r := range string(c) {
+		switch {
+		case r == zwj:
+			if m.ZWJ == ZWJJoined {
+				return part
+			}
+			w += part
+			part = 0
+		case r == vs16 && part > 0:
+			// Shown as emoji: those are wide.
+			part = 2
+		case part == 0:
+			part = Rune(r)
+		}
+	}
+	return w + part
+}
+
+// isRegional reports whether r is a regional indicator: two of them make a flag.
+func isRegional(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
@@ -0,0 +1,132 @@
+package width
+
+import "testing"
+
+func TestRune(t *testing.T) {
+	// Given
+	cases := []struct {
+		r    rune
+		want int
+	}{
+		{r: 'a', want: 1},
+		{r: 'é', want: 1},
+		{r: '\t', want: 0},
+		{r: 0x7F, want: 0},
+		{r: 0x9B, want: 0},
+		{r: 0xAD, want: 1},
+		{r: 0x301, want: 0},
+		{r: zwj, want: 0},
+		{r: vs16, want: 0},
+		{r: 0x1161, want: 0},
+		{r: '│', want: 1},
+		{r: '日', want: 2},
+		{r: 'ア', want: 2},
+		{r: 'ｱ', want: 1},
+		{r: 'Ａ', want: 2},
+		{r: '한', want: 2},
+		{r: '⌚', want: 2},
+		{r: '❤', want: 1},
+		{r: '🩷', want: 2},
+		{r: '😀', want: 2},
+		{r: 0x20000, want: 2},
+		// Wide since Unicode 16 and 17.
+		{r: 0x1FAE9, want: 2},
+		{r: 0x1FA89, want: 2},
+		{r: 0x1FAC8, want: 2},
+		{r: 0x2630, want: 2},
+		{r: 0x268F, want: 2},
+		{r: 0x4DC0, want: 2},
+		{r: 0x18D80, want: 2},
+	}
+
+	for _, c := range cases {
+		if got := Rune(c.r); got != c.want {
+			t.Errorf("err: %U: want (%d), got (%d)", c.r, c.want, got)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	// Given
+	cases := []struct {
+		s    string
+		want int
+	}{
+		{s: "", want: 0},
+		{s: "hello", want: 5},
+		{s: "text for u 🩷", want: 13},
+		{s: "é", want: 1},
+		{s: "日本語", want: 6},
+		{s: "❤️", want: 2},
+		{s: "❤︎", want: 1},
+		{s: "1️⃣", want: 2},
+		{s: "👍🏽", want: 2},
+		{s: "👩‍👩‍👧", want: 2},
+		{s: "🏳️‍🌈", want: 2},
+		{s: "a‍b", want: 2},
+		{s: "🇪🇸", want: 2},
+		{s: "🇪🇸🇫", want: 3},
+		{s: "한ᅡ", want: 2},
+		{s: "각", want: 2},
+		{s: "\x1b[1m", want: 3},
+		{s: "\xff\xfe", want: 2},
+	}
+
+	for _, c := range cases {
+		if got := String(c.s); got != c.want {
+			t.Errorf("err: %q: want (%d), got (%d)", c.s, c.want, got)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	// Given
+	cases := []struct {
+		s     string
+		cols  int
+		wantN int
+		wantW int
+	}{
+		{s: "hello", cols: 3, wantN: 3, wantW: 3},
+		{s: "hello", cols: 10, wantN: 5, wantW: 5},
+		{s: "hello", cols: 0, wantN: 0, wantW: 0},
+		{s: "ab日", cols: 3, wantN: 2, wantW: 2},
+		{s: "ab日", cols: 4, wantN: 5, wantW: 4},
+		{s: "abé", cols: 3, wantN: 5, wantW: 3},
+		{s: "a👩‍👩‍👧b", cols: 3, wantN: 19, wantW: 3},
+		{s: "a👩‍👩‍👧b", cols: 2, wantN: 1, wantW: 1},
+		{s: "a\u2630b", cols: 2, wantN: 1, wantW: 1},
+		{s: "a\U0001FAE9b", cols: 3, wantN: 5, wantW: 3},
+	}
+
+	for _, c := range cases {
+		n, w := Truncate([]byte(c.s), c.cols)
+		if n != c.wantN || w != c.wantW {
+			t.Errorf("err: %q, %d: want (%d, %d), got (%d, %d)", c.s, c.cols, c.wantN, c.wantW, n, w)
+		}
+	}
+}
+
+func TestZWJPolicy(t *testing.T) {
+	// Given
+	cases := []struct {
+		s      string
+		joined int
+		split  int
+	}{
+		{s: "👩‍👩‍👧", joined: 2, split: 6},
+		{s: "🏳️‍🌈", joined: 2, split: 4},
+		{s: "👍🏽‍", joined: 2, split: 2},
+		{s: "a‍b", joined: 2, split: 2},
+		{s: "🇪🇸", joined: 2, split: 2},
+	}
+
+	for _, c := range cases {
+		if got := (Measure{ZWJ: ZWJJoined}).String(c.s); got != c.joined {
+			t.Errorf("err: %q joined: want (%d), got (%d)", c.s, c.joined, got)
+		}
+		if got := (Measure{ZWJ: ZWJSplit}).String(c.s); got != c.split {
+			t.Errorf("err: %q split: want (%d), got (%d)", c.s, c.split, got)
+		}
+	}
+}